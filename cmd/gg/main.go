@@ -1,69 +1,629 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/Kaamkiya/gg/internal/app/connect4"
-	"github.com/Kaamkiya/gg/internal/app/dodger"
-	"github.com/Kaamkiya/gg/internal/app/hangman"
-	"github.com/Kaamkiya/gg/internal/app/maze"
-	"github.com/Kaamkiya/gg/internal/app/pong"
-	"github.com/Kaamkiya/gg/internal/app/snake"
-	"github.com/Kaamkiya/gg/internal/app/sudoku"
-	"github.com/Kaamkiya/gg/internal/app/tictactoe"
-	"github.com/Kaamkiya/gg/internal/app/twenty48"
-
-	"github.com/charmbracelet/huh"
+	"github.com/Kaamkiya/gg/internal/bot"
+	"github.com/Kaamkiya/gg/internal/cast"
+	"github.com/Kaamkiya/gg/internal/crash"
+	"github.com/Kaamkiya/gg/internal/daily"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/host"
+	"github.com/Kaamkiya/gg/internal/inspect"
+	"github.com/Kaamkiya/gg/internal/netplay"
+	"github.com/Kaamkiya/gg/internal/replay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/sshserver"
+	"github.com/Kaamkiya/gg/internal/stats"
+	"github.com/Kaamkiya/gg/internal/storage"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+
+	// Each game registers itself with the game package when imported.
+	_ "github.com/Kaamkiya/gg/internal/app/connect4"
+	_ "github.com/Kaamkiya/gg/internal/app/dodger"
+	_ "github.com/Kaamkiya/gg/internal/app/hangman"
+	_ "github.com/Kaamkiya/gg/internal/app/maze"
+	_ "github.com/Kaamkiya/gg/internal/app/pong"
+	_ "github.com/Kaamkiya/gg/internal/app/snake"
+	_ "github.com/Kaamkiya/gg/internal/app/sudoku"
+	_ "github.com/Kaamkiya/gg/internal/app/tictactoe"
+	_ "github.com/Kaamkiya/gg/internal/app/twenty48"
 )
 
+const usage = `gg - a tui for small offline games
+
+Usage:
+  gg [flags] [command]
+
+  gg                   choose a game from the menu
+  gg <game> [flags]    start a game straight away
+  gg <game> -h         show the flags a game accepts
+  gg list              list the available games
+  gg scores [game]     show the best scores for one or every game
+  gg stats [game]      show how much you played one or every game, and how it
+                       went
+  gg daily             show today's challenges and your streaks
+  gg daily <game>      play today's challenge for a game
+  gg replay <file>     watch a recorded game, e.g. the one shown after a game
+  gg host <game>       host a two-player game for someone on your network to
+                       join; --port sets the port, 4321 by default
+  gg join <host:port>  join a game someone is hosting
+  gg ssh               serve the menu and games over SSH, so others can play
+                       with ssh -p 2222 <host>; --port sets the port
+  gg serve-bot <game>  play a turn-based game over JSON lines on stdin and
+                       stdout, for scripts and bots
+  gg help              show this message
+
+Flags:
+  --debug              log every message the menu and games handle to
+                       debug.log, and show the state of the game with f12
+  --profile <name>     play as the profile called name, creating it if needed;
+                       without it, gg asks who's playing if there are several
+  --record <file>      record what is drawn to an asciinema v2 cast
+  --theme <name>       colour theme: default, high-contrast, solarized or
+                       monochrome, overriding the config file
+`
+
+// recordPath is where the session is recorded to, if anywhere.
+var recordPath string
+
+// debugLog is where --debug logs to.
+const debugLog = "debug.log"
+
+// profileSet is whether the profile was chosen with --profile.
+var profileSet bool
+
 func main() {
-	var game string
-
-	fmt.Println("gg - a tui for small offline games")
-
-	err := huh.NewSelect[string]().
-		Title("choose a game:").
-		Options(
-			huh.NewOption("2048", "twenty48"),
-			huh.NewOption("sudoku", "sudoku"),
-			huh.NewOption("dodger", "dodger"),
-			huh.NewOption("maze", "maze"),
-			huh.NewOption("hangman", "hangman"),
-			huh.NewOption("snake", "snake"),
-			huh.NewOption("connect 4 (2 player)", "connect4"),
-			huh.NewOption("pong (2 player)", "pong"),
-			huh.NewOption("tictactoe (2 player)", "tictactoe"),
-			huh.NewOption("tictactoe (vs AI)", "tictactoe-ai"),
-		).
-		Value(&game).
-		Run()
-	if err != nil {
-		fmt.Println("Error: failed to run selection menu.")
-		panic(err)
-	}
-
-	switch game {
-	case "maze":
-		maze.Run()
-	case "pong":
-		pong.Run()
-	case "tictactoe":
-		tictactoe.Run()
-	case "tictactoe-ai":
-		tictactoe.RunVsAi()
-	case "dodger":
-		dodger.Run()
-	case "hangman":
-		hangman.Run()
-	case "twenty48":
-		twenty48.Run()
-	case "connect4":
-		connect4.Run()
-	case "snake":
-		snake.Run()
-	case "sudoku":
-		sudoku.Run()
+	defer recoverCrash()
+
+	fs := flag.NewFlagSet("gg", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage) }
+	fs.StringVar(&recordPath, "record", "", "record what is drawn to an asciinema v2 cast")
+	debugging := fs.Bool("debug", false, "log the messages handled to "+debugLog+" and show the inspector with f12")
+	fs.Func("theme", "colour theme, overriding the config file", theme.Set)
+	fs.Func("profile", "profile to play as", setProfile)
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+
+	if *debugging {
+		f, err := inspect.Enable(debugLog)
+		if err != nil {
+			fail(fmt.Errorf("couldn't open the debug log: %w", err))
+		}
+		defer f.Close()
+	}
+
+	args := fs.Args()
+	if len(args) == 0 {
+		runMenu()
+		return
+	}
+
+	switch cmd := args[0]; cmd {
+	case "list":
+		listGames()
+	case "scores":
+		if err := showScores(args[1:]); err != nil {
+			fail(err)
+		}
+	case "stats":
+		if err := showStats(args[1:]); err != nil {
+			fail(err)
+		}
+	case "daily":
+		if err := runDaily(args[1:]); err != nil {
+			fail(err)
+		}
+	case "replay":
+		if err := runReplay(args[1:]); err != nil {
+			fail(err)
+		}
+	case "host":
+		if err := hostGame(args[1:]); err != nil {
+			fail(err)
+		}
+	case "join":
+		if err := joinGame(args[1:]); err != nil {
+			fail(err)
+		}
+	case "ssh":
+		if err := serveSSH(args[1:]); err != nil {
+			fail(err)
+		}
+	case "serve-bot":
+		if err := serveBot(args[1:]); err != nil {
+			fail(err)
+		}
+	case "help":
+		fmt.Print(usage)
 	default:
-		panic("This game either doesn't exist or hasn't been implemented.")
+		d, ok := game.Lookup(cmd)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown game or command %q.\n\n%s", cmd, usage)
+			os.Exit(2)
+		}
+
+		runGame(d, args[1:])
 	}
 }
+
+// run runs m, recording it if --record was given. It returns a
+// *crash.Error if gg panicked.
+func run(m host.Model) error {
+	c := crash.Catch(m)
+	if recordPath == "" {
+		if err := host.Run(c); err != nil {
+			return err
+		}
+		return c.Err()
+	}
+
+	rec := cast.NewRecorder(c)
+	if err := host.Run(rec); err != nil {
+		return err
+	}
+
+	if err := rec.Write(recordPath); err != nil {
+		return fmt.Errorf("couldn't write the recording: %w", err)
+	}
+	fmt.Printf("Recorded to %s.\n", recordPath)
+	return c.Err()
+}
+
+// bugReportURL is where crashes are reported.
+const bugReportURL = "https://github.com/Kaamkiya/gg/issues/new?template=bug_report.md"
+
+// fail reports err and exits. Crashes ask for a bug report.
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v.\n", err)
+
+	var crashErr *crash.Error
+	if errors.As(err, &crashErr) {
+		fmt.Fprintf(os.Stderr, "Sorry about that! Please open a bug report at %s", bugReportURL)
+		if crashErr.Err == nil {
+			fmt.Fprint(os.Stderr, " and attach the crash report")
+		}
+		fmt.Fprintln(os.Stderr, ".")
+	}
+
+	os.Exit(1)
+}
+
+// recoverCrash writes a crash report for a panic outside of a game, which
+// the host recovers from itself, and exits.
+func recoverCrash() {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	fail(crash.Save(crash.New(p, debug.Stack()), p))
+}
+
+func setProfile(name string) error {
+	if err := storage.CreateProfile(name); err != nil {
+		return err
+	}
+	profileSet = true
+	return storage.SetProfile(name)
+}
+
+func runMenu() {
+	m := host.NewChoosingProfile()
+	if profileSet {
+		m = host.New()
+	}
+
+	if err := run(m); err != nil {
+		fail(err)
+	}
+}
+
+func runGame(d game.Descriptor, args []string) {
+	newModel, seed, err := configure(d, args, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	if err := run(host.NewPlaying(d, args, newModel, seed)); err != nil {
+		fail(err)
+	}
+}
+
+// configure parses the flags of d from args and returns the constructor and
+// seed they select. Errors are reported to output.
+func configure(d game.Descriptor, args []string, output io.Writer) (game.Constructor, uint64, error) {
+	fs := flag.NewFlagSet("gg "+d.ID, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of gg %s:\n", d.ID)
+		fs.PrintDefaults()
+	}
+
+	newModel := d.New
+	if d.Flags != nil {
+		newModel = d.Flags(fs)
+	}
+
+	seed := game.RandomSeed()
+	fs.Func("seed", "seed for the random number generator, to replay a game exactly", func(s string) error {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a seed", s)
+		}
+		seed = n
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, 0, err
+	}
+
+	if fs.NArg() > 0 {
+		err := fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		fmt.Fprintf(output, "Error: %v.\n", err)
+		return nil, 0, err
+	}
+
+	return newModel, seed, nil
+}
+
+func hostGame(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no game given; you can host %s", strings.Join(versusGames(), ", "))
+	}
+
+	d, ok := game.Lookup(args[0])
+	if !ok || d.Versus == nil {
+		return fmt.Errorf("%q can't be played over the network; try one of %s", args[0], strings.Join(versusGames(), ", "))
+	}
+
+	fs := flag.NewFlagSet("gg host "+d.ID, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of gg host %s:\n", d.ID)
+		fs.PrintDefaults()
+	}
+	port := fs.Int("port", netplay.DefaultPort, "port to listen on")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	return netplay.Run(netplay.Host(d, ln))
+}
+
+func joinGame(args []string) error {
+	if len(args) != 1 {
+		return errors.New("give the address of the host, e.g. gg join 192.168.1.20:4321")
+	}
+
+	addr := args[0]
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, strconv.Itoa(netplay.DefaultPort))
+	}
+
+	c, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return netplay.Run(netplay.Join(c))
+}
+
+// versusGames returns the IDs of the games that can be played over the
+// network.
+func versusGames() []string {
+	var ids []string
+	for _, d := range game.All() {
+		if d.Versus != nil {
+			ids = append(ids, d.ID)
+		}
+	}
+	return ids
+}
+
+func serveSSH(args []string) error {
+	fs := flag.NewFlagSet("gg ssh", flag.ContinueOnError)
+	port := fs.Int("port", sshserver.DefaultPort, "port to listen on")
+	addr := fs.String("addr", "", "address to listen on; every address by default")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	s, err := sshserver.New(net.JoinHostPort(*addr, strconv.Itoa(*port)))
+	if err != nil {
+		return err
+	}
+	return sshserver.ListenAndServe(s)
+}
+
+func serveBot(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no game given; bots can play %s", strings.Join(botGames(), ", "))
+	}
+
+	d, ok := game.Lookup(args[0])
+	if !ok || d.Bot == nil {
+		return fmt.Errorf("%q can't be played by a bot; try one of %s", args[0], strings.Join(botGames(), ", "))
+	}
+
+	fs := flag.NewFlagSet("gg serve-bot "+d.ID, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of gg serve-bot %s:\n", d.ID)
+		fs.PrintDefaults()
+	}
+
+	newBot := d.Bot(fs)
+	seed := fs.Uint64("seed", game.RandomSeed(), "seed for the random number generator, to play the same game again")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	defer recoverBot(d, *seed, args[1:])
+	return bot.Serve(d, newBot(game.NewRand(*seed)), os.Stdin, os.Stdout)
+}
+
+// recoverBot writes a crash report for a panic while a bot played d, started
+// from seed with the flags args, and exits.
+func recoverBot(d game.Descriptor, seed uint64, args []string) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	r := crash.New(p, debug.Stack())
+	r.Game = d.ID
+	r.Seed = seed
+	r.Args = args
+	fail(crash.Save(r, p))
+}
+
+// botGames returns the IDs of the games bots can play.
+func botGames() []string {
+	var ids []string
+	for _, d := range game.All() {
+		if d.Bot != nil {
+			ids = append(ids, d.ID)
+		}
+	}
+	return ids
+}
+
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("gg replay", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage of gg replay: gg replay [flags] <file>")
+		fs.PrintDefaults()
+	}
+
+	speed := 1
+	game.IntRangeVar(fs, &speed, "speed", "playback speed", 1, 2)
+	step := fs.Bool("step", false, "play one event per key press")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		os.Exit(2)
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	f, err := replay.Read(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	d, ok := game.Lookup(f.Game)
+	if !ok {
+		return fmt.Errorf("unknown game %q", f.Game)
+	}
+
+	var model tea.Model
+	if f.Resume != nil {
+		if d.Resume == nil {
+			return fmt.Errorf("%s can't be resumed", d.ID)
+		}
+		model, err = d.Resume(game.Save{Version: f.Resume.Version, State: f.Resume.State}, game.NewRand(f.Seed))
+	} else {
+		var newModel game.Constructor
+		newModel, _, err = configure(d, f.Args, io.Discard)
+		if err == nil {
+			model = newModel(game.NewRand(f.Seed))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't start %s: %w", d.ID, err)
+	}
+
+	// Watching a run again shouldn't add its score a second time.
+	scores.SetReadOnly(true)
+
+	return run(host.NewReplay(d, model, f, speed, *step))
+}
+
+func listGames() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GAME\tPLAYERS\tCATEGORY\tDESCRIPTION")
+
+	for _, d := range game.All() {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", d.ID, d.Players, d.Category, d.Description)
+	}
+
+	w.Flush()
+}
+
+func showScores(args []string) error {
+	store, err := scores.Default()
+	if err != nil {
+		return err
+	}
+
+	games := args
+	if len(games) == 0 {
+		if games, err = store.Games(); err != nil {
+			return err
+		}
+		if len(games) == 0 {
+			fmt.Println("No scores yet. Go play something!")
+			return nil
+		}
+	}
+
+	for i, id := range games {
+		d, ok := game.Lookup(id)
+		if !ok {
+			return fmt.Errorf("unknown game %q", id)
+		}
+
+		top, err := store.Top(id)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(d.Label())
+
+		if len(top) == 0 {
+			fmt.Println("  no scores yet")
+			continue
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "  #\tSCORE\tDATE")
+		for rank, e := range top {
+			fmt.Fprintf(w, "  %d\t%d\t%s\n", rank+1, e.Score, e.Time.Format("2006-01-02 15:04"))
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+func showStats(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument %q", args[1])
+	}
+
+	sessions, err := stats.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		d, ok := game.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("unknown game %q", args[0])
+		}
+		sessions = slices.DeleteFunc(sessions, func(s stats.Session) bool {
+			return s.Game != d.ID
+		})
+	}
+
+	width, _, err := term.GetSize(os.Stdout.Fd())
+	if err != nil {
+		width = 80
+	}
+
+	fmt.Println(stats.Render(sessions, width, theme.Current()))
+	return nil
+}
+
+func runDaily(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument %q", args[1])
+	}
+
+	if len(args) == 1 {
+		d, ok := game.Lookup(args[0])
+		if !ok || !d.Daily {
+			return fmt.Errorf("%q has no daily challenge", args[0])
+		}
+		return run(host.NewDaily(d))
+	}
+
+	today := daily.Today()
+	fmt.Printf("Daily challenges for %s\n\n", today)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GAME\tTODAY\tTIME\tMOVES\tSTREAK\tBEST STREAK")
+
+	for _, d := range game.All() {
+		if !d.Daily {
+			continue
+		}
+
+		r, err := daily.Load(d.ID)
+		if err != nil {
+			return err
+		}
+
+		status, took, moves := "not played", "-", "-"
+		if e, ok := r.Days[today]; ok {
+			status = "solved"
+			if !e.Won {
+				status = "not solved"
+			}
+			took = e.Time.String()
+			moves = strconv.Itoa(e.Moves)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", d.ID, status, took, moves, r.CurrentStreak(today), r.BestStreak)
+	}
+
+	return w.Flush()
+}
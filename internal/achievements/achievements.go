@@ -0,0 +1,288 @@
+// Package achievements unlocks achievements as games publish events, and
+// keeps the ones each profile unlocked.
+package achievements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/storage"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// step is what an event does to the progress towards an achievement.
+type step int
+
+const (
+	none  step = iota // Nothing.
+	count             // Counts towards it.
+	reset             // Breaks a streak, so counting starts again.
+)
+
+// Achievement is something to aim for in a game.
+type Achievement struct {
+	ID          string
+	Name        string
+	Description string
+	Game        string // The ID of the game it is unlocked in.
+
+	// Goal is how many events have to count towards the achievement, in a
+	// row if any event resets it.
+	Goal int
+
+	step func(e game.Event) step
+}
+
+// when returns a step that counts the events called name that match ok.
+func when(name string, ok func(value int) bool) func(game.Event) step {
+	return func(e game.Event) step {
+		if e.Name == name && ok(e.Value) {
+			return count
+		}
+		return none
+	}
+}
+
+func atLeast(n int) func(int) bool {
+	return func(v int) bool { return v >= n }
+}
+
+func always(int) bool {
+	return true
+}
+
+// All lists every achievement, in the order they are shown.
+var All = []Achievement{
+	{ID: "snake-20", Name: "Long boi", Description: "Grow the snake to a length of 20.", Game: "snake", Goal: 1, step: when("ate", atLeast(20))},
+	{ID: "snake-50", Name: "Noodle", Description: "Grow the snake to a length of 50.", Game: "snake", Goal: 1, step: when("ate", atLeast(50))},
+	{ID: "twenty48-512", Name: "Halfway there", Description: "Make a 512 tile in 2048.", Game: "twenty48", Goal: 1, step: when("merged", atLeast(512))},
+	{ID: "twenty48-2048", Name: "2048", Description: "Make the 2048 tile.", Game: "twenty48", Goal: 1, step: when("merged", atLeast(2048))},
+	{ID: "sudoku-clean", Name: "Clean sheet", Description: "Finish a sudoku without entering a number that clashes with another.", Game: "sudoku", Goal: 1, step: when("solved", func(mistakes int) bool { return mistakes == 0 })},
+	{ID: "maze-solved", Name: "Way out", Description: "Solve a maze.", Game: "maze", Goal: 1, step: when("solved", always)},
+	{ID: "maze-optimal", Name: "No wrong turns", Description: "Solve a maze in the fewest moves possible.", Game: "maze", Goal: 1, step: when("solved-optimally", always)},
+	{ID: "hangman-clean", Name: "Mind reader", Description: "Guess a hangman word without a wrong letter.", Game: "hangman", Goal: 1, step: when("guessed", func(wrong int) bool { return wrong == 0 })},
+	{ID: "ai-beaten", Name: "Man beats machine", Description: "Beat the tictactoe AI.", Game: "tictactoe-ai", Goal: 1, step: when("won-match", always)},
+	{
+		ID: "ai-streak", Name: "Unbeatable", Description: "Beat the tictactoe AI 5 times in a row.", Game: "tictactoe-ai", Goal: 5,
+		step: func(e game.Event) step {
+			switch e.Name {
+			case "won-match":
+				return count
+			case "lost-match", "drew-match":
+				return reset
+			}
+			return none
+		},
+	},
+}
+
+// Lookup returns the achievement with the given ID.
+func Lookup(id string) (Achievement, bool) {
+	for _, a := range All {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Achievement{}, false
+}
+
+// Record is what a profile unlocked, and how far it got with the rest.
+type Record struct {
+	Unlocked map[string]time.Time `json:"unlocked"`
+	Progress map[string]int       `json:"progress,omitempty"`
+}
+
+func path() (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "achievements.json"), nil
+}
+
+func read(p string) (Record, error) {
+	r := Record{Unlocked: map[string]time.Time{}, Progress: map[string]int{}}
+
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, err
+	}
+	if r.Unlocked == nil {
+		r.Unlocked = map[string]time.Time{}
+	}
+	if r.Progress == nil {
+		r.Progress = map[string]int{}
+	}
+	return r, nil
+}
+
+// Load returns the record of the current profile.
+func Load() (Record, error) {
+	p, err := path()
+	if err != nil {
+		return Record{}, err
+	}
+
+	return read(p)
+}
+
+// Apply counts e towards the achievements of its game, and returns the ones
+// it unlocked.
+func (r *Record) Apply(e game.Event, now time.Time) []Achievement {
+	var unlocked []Achievement
+	for _, a := range All {
+		if a.Game != e.Game {
+			continue
+		}
+		if _, done := r.Unlocked[a.ID]; done {
+			continue
+		}
+
+		switch a.step(e) {
+		case count:
+			r.Progress[a.ID]++
+		case reset:
+			delete(r.Progress, a.ID)
+			continue
+		default:
+			continue
+		}
+
+		if r.Progress[a.ID] >= a.Goal {
+			delete(r.Progress, a.ID)
+			r.Unlocked[a.ID] = now
+			unlocked = append(unlocked, a)
+		}
+	}
+	return unlocked
+}
+
+// UnlockedMsg is sent when an event unlocked an achievement.
+type UnlockedMsg struct {
+	Achievement Achievement
+}
+
+// Observe returns a command that counts e towards the achievements of the
+// current profile, and sends an UnlockedMsg for each one it unlocked.
+// Subscribe it to a game.Bus. Events that can't count towards anything are
+// dropped straight away, and the record is read and saved by the command, so
+// frequent events like moves don't hold up the game. Like scores,
+// achievements can't be unlocked while watching a replay, and aren't worth
+// interrupting a game for if they can't be saved.
+func Observe(e game.Event) tea.Cmd {
+	if scores.ReadOnly() || !counts(e) {
+		return nil
+	}
+
+	return func() tea.Msg {
+		unlocked, err := observe(e)
+		if err != nil || len(unlocked) == 0 {
+			return nil
+		}
+
+		var cmds tea.BatchMsg
+		for _, a := range unlocked {
+			cmds = append(cmds, func() tea.Msg { return UnlockedMsg{Achievement: a} })
+		}
+		return cmds
+	}
+}
+
+func observe(e game.Event) ([]Achievement, error) {
+	p, err := path()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := storage.Lock(p)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	r, err := read(p)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked := r.Apply(e, time.Now())
+	if len(unlocked) == 0 && !tracks(e) {
+		return nil, nil
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return unlocked, storage.WriteFile(p, data)
+}
+
+// counts reports whether e can change the progress of any achievement, or
+// unlock one.
+func counts(e game.Event) bool {
+	for _, a := range All {
+		if a.Game == e.Game && a.step(e) != none {
+			return true
+		}
+	}
+	return false
+}
+
+// tracks reports whether e can change the progress of any achievement, so
+// the record needs saving.
+func tracks(e game.Event) bool {
+	for _, a := range All {
+		if a.Game == e.Game && a.Goal > 1 && a.step(e) != none {
+			return true
+		}
+	}
+	return false
+}
+
+// Render lists every achievement, saying when each unlocked one was
+// unlocked and how far r got with the others.
+func Render(r Record, t theme.Theme) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d of %d unlocked\n", len(r.Unlocked), len(All))
+	for _, a := range All {
+		name := a.Game
+		if d, ok := game.Lookup(a.Game); ok {
+			name = d.Label()
+		}
+
+		b.WriteString("\n")
+		if at, ok := r.Unlocked[a.ID]; ok {
+			fmt.Fprintf(&b, "%s %s  %s\n", t.Highlight.Render("★"), t.Highlight.Render(a.Name), name)
+			fmt.Fprintf(&b, "  %s Unlocked %s.\n", a.Description, at.Format("Jan 2 2006"))
+			continue
+		}
+
+		fmt.Fprintf(&b, "☆ %s  %s\n", a.Name, name)
+		if n := r.Progress[a.ID]; n > 0 {
+			fmt.Fprintf(&b, "  %s %d of %d so far.\n", a.Description, n, a.Goal)
+		} else {
+			fmt.Fprintf(&b, "  %s\n", a.Description)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,101 @@
+package achievements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/scores"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var now = time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+
+func ai(name string) game.Event {
+	return game.Event{Game: "tictactoe-ai", Name: name}
+}
+
+func TestApply(t *testing.T) {
+	r := Record{Unlocked: map[string]time.Time{}, Progress: map[string]int{}}
+
+	if got := r.Apply(game.Event{Game: "snake", Name: "ate", Value: 19}, now); len(got) != 0 {
+		t.Errorf("a snake of 19 unlocked %v", got)
+	}
+	if got := r.Apply(game.Event{Game: "snake", Name: "ate", Value: 20}, now); len(got) != 1 || got[0].ID != "snake-20" {
+		t.Errorf("a snake of 20 unlocked %v, want snake-20", got)
+	}
+	if got := r.Apply(game.Event{Game: "snake", Name: "ate", Value: 21}, now); len(got) != 0 {
+		t.Errorf("snake-20 was unlocked again: %v", got)
+	}
+
+	// Another game's events don't count.
+	r.Apply(game.Event{Game: "sudoku", Name: "won-match"}, now)
+	if r.Progress["ai-streak"] != 0 {
+		t.Errorf("a sudoku event counted towards the AI streak")
+	}
+
+	for range 4 {
+		r.Apply(ai("won-match"), now)
+	}
+	r.Apply(ai("drew-match"), now)
+	if r.Progress["ai-streak"] != 0 {
+		t.Errorf("a draw left the streak at %d, want 0", r.Progress["ai-streak"])
+	}
+
+	var unlocked []Achievement
+	for range 5 {
+		unlocked = append(unlocked, r.Apply(ai("won-match"), now)...)
+	}
+	if len(unlocked) != 1 || unlocked[0].ID != "ai-streak" {
+		t.Errorf("five wins in a row unlocked %v, want ai-streak", unlocked)
+	}
+	if _, ok := r.Unlocked["ai-beaten"]; !ok {
+		t.Errorf("ai-beaten isn't unlocked after beating the AI")
+	}
+}
+
+func TestObserve(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	cmd := Observe(game.Event{Game: "maze", Name: "solved", Value: 40})
+	if cmd == nil {
+		t.Fatal("solving a maze unlocked nothing")
+	}
+	batch, _ := cmd().(tea.BatchMsg)
+	if len(batch) != 1 {
+		t.Fatalf("solving a maze unlocked %d achievements, want 1", len(batch))
+	}
+	if msg, ok := batch[0]().(UnlockedMsg); !ok || msg.Achievement.ID != "maze-solved" {
+		t.Errorf("got %#v, want maze-solved to be unlocked", msg)
+	}
+
+	Observe(ai("won-match"))()
+	Observe(ai("won-match"))()
+
+	// Events that count for nothing aren't even looked at.
+	if cmd := Observe(game.Event{Game: "twenty48", Name: "merged", Value: 8}); cmd != nil {
+		t.Errorf("merging two 4s returned a command")
+	}
+
+	// Watching a replay unlocks nothing.
+	scores.SetReadOnly(true)
+	if cmd := Observe(game.Event{Game: "maze", Name: "solved-optimally"}); cmd != nil {
+		t.Errorf("a replay returned a command")
+	}
+	scores.SetReadOnly(false)
+
+	r, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Unlocked["maze-solved"]; !ok {
+		t.Errorf("maze-solved wasn't kept: %+v", r)
+	}
+	if _, ok := r.Unlocked["maze-optimal"]; ok {
+		t.Errorf("maze-optimal was unlocked by a replay")
+	}
+	if r.Progress["ai-streak"] != 2 {
+		t.Errorf("the AI streak is %d, want 2", r.Progress["ai-streak"])
+	}
+}
@@ -0,0 +1,60 @@
+package connect4
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// bot plays connect 4 for both players.
+type bot struct {
+	m model
+}
+
+type botState struct {
+	Board []string `json:"board"` // One string per row, top to bottom, with "." for empty cells.
+	Turn  string   `json:"turn"`
+}
+
+func newBot(*rand.Rand) game.Bot {
+	return &bot{m: model{board: emptyBoard(), turn: 'x'}}
+}
+
+func (b *bot) State() any {
+	rows := make([]string, len(b.m.board))
+	for y, row := range b.m.board {
+		rows[y] = strings.ReplaceAll(string(row[:]), " ", ".")
+	}
+
+	return botState{Board: rows, Turn: string(b.m.turn)}
+}
+
+// Moves returns the numbers of the columns that aren't full, from 1.
+func (b *bot) Moves() []string {
+	var moves []string
+	for x, cell := range b.m.board[0] {
+		if cell == ' ' {
+			moves = append(moves, strconv.Itoa(x+1))
+		}
+	}
+	return moves
+}
+
+func (b *bot) Play(move string) {
+	col, _ := strconv.Atoi(move)
+	b.m.drop(col - 1)
+}
+
+func (b *bot) Result() (game.Result, bool) {
+	switch winner := b.m.CheckForWin(); winner {
+	case ' ':
+		return game.Result{}, false
+	case 't':
+		return game.Result{Outcome: game.Draw}, true
+	default:
+		return game.Result{Outcome: game.Won, Summary: fmt.Sprintf("%c wins!", winner)}, true
+	}
+}
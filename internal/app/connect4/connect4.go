@@ -1,35 +1,157 @@
 package connect4
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"math/rand/v2"
 	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/theme"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 type model struct {
-	board [6][7]rune // [y][x]
-	turn  rune
+	board  [6][7]rune // [y][x]
+	turn   rune
+	cursor int       // The column a piece is dropped in.
+	seat   game.Seat // Who the player is in a network game; 0 when both share the keyboard.
+	done   bool      // Set once the game is won or drawn, so it only ends once.
 
-	xStyle lipgloss.Style
-	oStyle lipgloss.Style
+	theme   theme.Theme
+	keys    keys.Map
+	overlay overlay.Model
 }
 
+const description = "Drop pieces in turn and line up four in a row."
+
+// column is the action of dropping a piece in the column with the number
+// typed.
+const column = "column"
+
+var defaultKeys = append([]keys.Default{
+	{Action: keys.Left, Keys: []string{"left", "h"}, Help: "left"},
+	{Action: keys.Right, Keys: []string{"right", "l"}, Help: "right"},
+	{Action: keys.Drop, Keys: []string{" ", "enter"}, Help: "drop"},
+	{Action: column, Keys: []string{"1", "2", "3", "4", "5", "6", "7"}, Help: "drop in column", Label: "1-7"},
+}, overlay.Keys("q")...)
+
 func initialModel() tea.Model {
+	t := theme.Current()
+	k := keys.For("connect4", defaultKeys...)
+
+	return model{
+		board:   emptyBoard(),
+		turn:    'x',
+		theme:   t,
+		keys:    k,
+		overlay: overlay.New(t, k, description),
+	}
+}
+
+// emptyBoard returns a board without any pieces.
+func emptyBoard() [6][7]rune {
 	board := [6][7]rune{}
 	for y := range board {
 		for x := range board[y] {
 			board[y][x] = ' '
 		}
 	}
+	return board
+}
+
+// saveVersion is the version of the saved struct's format.
+const saveVersion = 1
+
+type saved struct {
+	Board []string `json:"board"` // One string per row, top to bottom.
+	Turn  string   `json:"turn"`
+}
+
+func (m model) Save() (int, any) {
+	rows := make([]string, len(m.board))
+	for y, row := range m.board {
+		rows[y] = string(row[:])
+	}
 
-	return model{
-		board:  board,
-		turn:   'x',
-		xStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
-		oStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+	return saveVersion, saved{
+		Board: rows,
+		Turn:  string(m.turn),
+	}
+}
+
+func resume(s game.Save, _ *rand.Rand) (tea.Model, error) {
+	var st saved
+	if err := s.Decode(saveVersion, &st); err != nil {
+		return nil, err
+	}
+
+	m := initialModel().(model)
+	if err := m.load(st); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// load replaces the board and turn of m with those in st.
+func (m *model) load(st saved) error {
+	if len(st.Board) != len(m.board) {
+		return fmt.Errorf("board has %d rows", len(st.Board))
+	}
+
+	for y, row := range st.Board {
+		cells := []rune(row)
+		if len(cells) != len(m.board[y]) {
+			return fmt.Errorf("board row has %d cells", len(cells))
+		}
+		copy(m.board[y][:], cells)
+	}
+
+	if st.Turn != "x" && st.Turn != "o" {
+		return fmt.Errorf("invalid turn %q", st.Turn)
+	}
+	m.turn = rune(st.Turn[0])
+
+	return nil
+}
+
+// newVersus returns a model for the player in seat of a network game. The
+// host plays x and goes first.
+func newVersus(seat game.Seat) game.Versus {
+	m := initialModel().(model)
+	m.seat = seat
+	return m
+}
+
+// piece returns the piece the player in seat plays with.
+func piece(seat game.Seat) rune {
+	if seat == game.Guest {
+		return 'o'
+	}
+	return 'x'
+}
+
+func (m model) State() any {
+	_, st := m.Save()
+	return st
+}
+
+func (m model) Sync(state json.RawMessage) (game.Versus, tea.Cmd, error) {
+	var st saved
+	if err := json.Unmarshal(state, &st); err != nil {
+		return m, nil, err
 	}
+	if err := m.load(st); err != nil {
+		return m, nil, err
+	}
+
+	cmd := m.end()
+	return m, cmd, nil
 }
 
 func (m model) Init() tea.Cmd {
@@ -37,55 +159,132 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m, game.Over(game.Result{Outcome: game.Quit})
+	case overlay.Handled:
+		return m, nil
+	}
+
+	if m.done {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "1", "2", "3", "4", "5", "6", "7":
-			/* Don't check for errors because there can't be one.
-			 * This only gets called if an integer was inputted.
-			 */
-			col, _ := strconv.Atoi(msg.String())
-			col-- // Go is 0 indexed, inputs are not.
-
-			// A piece can only go in that column if it's not full.
-			if m.board[0][col] == ' ' {
-				for y := len(m.board) - 1; y >= 0; y-- {
-					if m.board[y][col] == ' ' {
-						m.board[y][col] = m.turn
-
-						if m.turn == 'x' {
-							m.turn = 'o'
-						} else {
-							m.turn = 'x'
-						}
-
-						break
-					}
-				}
+		switch {
+		case m.keys.Matches(msg, keys.Left):
+			m.cursor = max(m.cursor-1, 0)
+		case m.keys.Matches(msg, keys.Right):
+			m.cursor = min(m.cursor+1, len(m.board[0])-1)
+		case m.keys.Matches(msg, keys.Drop):
+			if m.seat != 0 {
+				return m, m.send(m.cursor)
+			}
+			m.drop(m.cursor)
+		case m.keys.Matches(msg, column):
+			col, err := strconv.Atoi(msg.String())
+			if err != nil || col < 1 || col > len(m.board[0]) {
+				break
 			}
+
+			m.cursor = col - 1 // Go is 0 indexed, inputs are not.
+			if m.seat != 0 {
+				return m, m.send(m.cursor)
+			}
+			m.drop(m.cursor)
+		}
+	case game.MoveMsg:
+		// Only the host plays moves, and only in turn.
+		col, err := strconv.Atoi(msg.Move)
+		if m.seat != game.Host || piece(msg.Seat) != m.turn || err != nil || col < 1 || col > len(m.board[0]) {
+			return m, nil
 		}
+		m.drop(col - 1)
 	}
 
-	if m.CheckForWin() != ' ' {
-		return m, tea.Quit
+	cmd := m.end()
+	return m, cmd
+}
+
+// send returns a command that sends the player's move in a network game,
+// or nil if it isn't their turn.
+func (m model) send(col int) tea.Cmd {
+	if m.turn != piece(m.seat) || m.CheckForWin() != ' ' {
+		return nil
 	}
+	return game.Move(m.seat, strconv.Itoa(col+1))
+}
 
-	return m, nil
+// over returns a command that ends the game if someone has won or the board
+// is full, or nil if it isn't over. In a network game the result is the
+// player's own.
+func (m model) over() tea.Cmd {
+	switch winner := m.CheckForWin(); winner {
+	case ' ':
+		return nil
+	case 't':
+		return game.Over(game.Result{Outcome: game.Draw})
+	default:
+		outcome := game.Won
+		if m.seat != 0 && winner != piece(m.seat) {
+			outcome = game.Lost
+		}
+		return game.Over(game.Result{
+			Outcome: outcome,
+			Summary: fmt.Sprintf("%c wins!", winner),
+		})
+	}
+}
+
+// end returns the command of over the first time the game is over, and nil
+// after that.
+func (m *model) end() tea.Cmd {
+	if m.done {
+		return nil
+	}
+
+	cmd := m.over()
+	m.done = cmd != nil
+	return cmd
+}
+
+// drop puts a piece for the current player in col, and passes the turn.
+func (m *model) drop(col int) {
+	// A piece can only go in that column if it's not full.
+	if m.board[0][col] != ' ' {
+		return
+	}
+
+	for y := len(m.board) - 1; y >= 0; y-- {
+		if m.board[y][col] == ' ' {
+			m.board[y][col] = m.turn
+
+			if m.turn == 'x' {
+				m.turn = 'o'
+			} else {
+				m.turn = 'x'
+			}
+
+			return
+		}
+	}
 }
 
 func (m model) View() string {
-	s := "| 1 | 2 | 3 | 4 | 5 | 6 | 7 |\n"
+	s := strings.Repeat(" ", 2+m.cursor*4) + "v\n"
+	s += "| 1 | 2 | 3 | 4 | 5 | 6 | 7 |\n"
 	s += "+---------------------------+\n"
 
 	for _, row := range m.board {
 		s += "| "
 		for _, cell := range row {
-			style := m.oStyle
+			style := m.theme.Player2
 
 			if cell == 'x' {
-				style = m.xStyle
+				style = m.theme.Player1
 			}
 
 			s += style.Render(string(cell)) + " | "
@@ -97,14 +296,23 @@ func (m model) View() string {
 
 	switch m.CheckForWin() {
 	case ' ':
-		s += fmt.Sprintf("\n%c's turn\n", m.turn)
+		switch {
+		case m.seat == 0:
+			s += fmt.Sprintf("\n%c's turn\n", m.turn)
+		case m.turn == piece(m.seat):
+			s += fmt.Sprintf("\nYour turn, you are %c\n", m.turn)
+		default:
+			s += fmt.Sprintf("\nWaiting for %c\n", m.turn)
+		}
 	case 't':
 		s += "\ntie!\n"
 	default:
 		s += fmt.Sprintf("\n%c wins!\n", m.CheckForWin())
 	}
 
-	return s
+	s += "\n" + m.overlay.Help()
+
+	return m.overlay.View(s)
 }
 
 func (m model) CheckForWin() rune {
@@ -185,10 +393,18 @@ func (m model) CheckForWin() rune {
 	return ' '
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "connect4",
+		Name:        "connect 4",
+		Players:     2,
+		Category:    game.Board,
+		Description: description,
+		New:         func(*rand.Rand) tea.Model { return initialModel() },
+		Resume:      resume,
+		Bot: func(*flag.FlagSet) game.BotConstructor {
+			return newBot
+		},
+		Versus: newVersus,
+	})
 }
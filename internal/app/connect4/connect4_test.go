@@ -0,0 +1,141 @@
+package connect4
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/gametest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newModel(*rand.Rand) tea.Model {
+	return initialModel()
+}
+
+func TestWin(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string // Columns to drop in, x and o taking turns.
+		winner string
+	}{
+		{"across", []string{"1", "1", "2", "2", "3", "3", "4"}, "x wins!"},
+		{"down", []string{"1", "2", "1", "2", "1", "2", "1"}, "x wins!"},
+		{"down right", []string{"1", "2", "2", "3", "3", "4", "3", "4", "4", "7", "4"}, "x wins!"},
+		{"down left", []string{"7", "6", "6", "5", "5", "4", "5", "4", "4", "1", "4"}, "x wins!"},
+		{"o", []string{"1", "2", "1", "2", "1", "2", "7", "2"}, "o wins!"},
+		{"right edge", []string{"7", "6", "7", "6", "7", "6", "7"}, "x wins!"},
+		{"second row", []string{"1", "2", "4", "3", "1", "7", "2", "7", "3", "7", "4"}, "x wins!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gametest.Start(t, newModel, 1)
+			g.Keys(tt.keys[:len(tt.keys)-1]...)
+			if _, over := g.Result(); over {
+				t.Fatal("the game ended before the last piece")
+			}
+
+			g.Keys(tt.keys[len(tt.keys)-1])
+			r, _ := g.Result()
+			if r.Outcome != game.Won || r.Summary != tt.winner {
+				t.Errorf("got %v %q, want won %q", r.Outcome, r.Summary, tt.winner)
+			}
+
+			// The game only ends once, whatever comes after.
+			for _, msg := range []tea.Msg{gametest.Key("5"), game.MoveMsg{Seat: game.Host, Move: "5"}} {
+				if _, cmd := g.Model().Update(msg); cmd != nil {
+					t.Errorf("%v after the win returned a command", msg)
+				}
+			}
+		})
+	}
+}
+
+func TestNoWin(t *testing.T) {
+	g := gametest.Start(t, newModel, 1)
+
+	// Three in a row, broken by o, and four across two rows.
+	g.Keys("1", "4", "2", "5", "3", "4", "5", "6", "6")
+	if r, over := g.Result(); over {
+		t.Fatalf("the game ended with %v %q", r.Outcome, r.Summary)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	g := gametest.Start(t, newModel, 1)
+
+	// Fill the columns in pairs, so no four line up anywhere:
+	//	| x o x o x o x |
+	//	| x o x o x o x |
+	//	| o x o x o x o | ...
+	m := g.Model().(model)
+	for y := range m.board {
+		for x := range m.board[y] {
+			if (x+y/2)%2 == 0 {
+				m.board[y][x] = 'x'
+			} else {
+				m.board[y][x] = 'o'
+			}
+		}
+	}
+	m.board[0][6] = ' '
+	g.SetModel(m)
+
+	g.Keys("7")
+	if r, _ := g.Result(); r.Outcome != game.Draw {
+		t.Errorf("filling the board gave %v, want draw", r.Outcome)
+	}
+}
+
+func TestFullColumn(t *testing.T) {
+	g := gametest.Start(t, newModel, 1)
+
+	g.Keys("3", "3", "3", "3", "3", "3", "3")
+	m := g.Model().(model)
+	if m.turn != 'x' {
+		t.Errorf("dropping in a full column passed the turn to %c", m.turn)
+	}
+	g.Golden("full_column")
+}
+
+func TestCursor(t *testing.T) {
+	g := gametest.Start(t, newModel, 1)
+
+	g.Keys("left", "right", "right", " ")
+	if m := g.Model().(model); m.board[5][2] != 'x' {
+		t.Errorf("piece not dropped under the cursor:\n%s", g.View())
+	}
+}
+
+func TestVersusTurns(t *testing.T) {
+	g := gametest.Start(t, func(*rand.Rand) tea.Model { return newVersus(game.Host) }, 1)
+
+	g.Keys("1")
+	g.Send(game.MoveMsg{Seat: game.Host, Move: "2"}) // Out of turn.
+	g.Keys("3")                                      // Also out of turn.
+
+	m := g.Model().(model)
+	if m.board[5][0] != 'x' || m.board[5][1] != ' ' || m.board[5][2] != ' ' || m.turn != 'o' {
+		t.Fatalf("moves out of turn were played:\n%s", g.View())
+	}
+
+	g.Send(game.MoveMsg{Seat: game.Guest, Move: "2"})
+	if m := g.Model().(model); m.board[5][1] != 'o' || m.turn != 'x' {
+		t.Fatalf("the guest's move wasn't played:\n%s", g.View())
+	}
+}
+
+func TestVersusResult(t *testing.T) {
+	g := gametest.Start(t, func(*rand.Rand) tea.Model { return newVersus(game.Host) }, 1)
+
+	// The guest gets four down first.
+	for _, col := range []string{"1", "3", "5", "7"} {
+		g.Send(game.MoveMsg{Seat: game.Host, Move: col})
+		g.Send(game.MoveMsg{Seat: game.Guest, Move: "2"})
+	}
+	if r, _ := g.Result(); r.Outcome != game.Lost {
+		t.Errorf("the host got %v when the guest won, want lost", r.Outcome)
+	}
+}
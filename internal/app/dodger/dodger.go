@@ -1,17 +1,18 @@
 package dodger
 
 import (
+	"flag"
 	"fmt"
 	"math/rand/v2"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
-)
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/theme"
 
-type (
-	spawnBlockMsg struct{}
-	moveBlockMsg  struct{}
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 type vector struct {
@@ -24,74 +25,156 @@ type model struct {
 	player vector   // The position of the player.
 	blocks []vector // The positions of each block on the screen.
 	score  int      // The amount of blocks that have gone off-screen.
+	best   int      // The best score from previous games.
+	rnd    *rand.Rand
+
+	theme   theme.Theme
+	keys    keys.Map
+	overlay overlay.Model
+	clock   game.Clock // Spawns a block and moves every block down.
+	fit     bool       // Whether to size the screen to the terminal once its size is known.
+}
+
+// The blocks start falling one row every startInterval, and fall faster
+// for every 10 blocks dodged, up to one row every minInterval.
+const (
+	startInterval = 200 * time.Millisecond
+	minInterval   = 80 * time.Millisecond
+	speedUp       = 10 * time.Millisecond
+)
+
+const description = "Move left and right to dodge the falling blocks."
+
+var defaultKeys = append([]keys.Default{
+	{Action: keys.Left, Keys: []string{"left", "h"}, Help: "left"},
+	{Action: keys.Right, Keys: []string{"right", "l"}, Help: "right"},
+}, overlay.Keys("q")...)
 
-	blockStyle  lipgloss.Style
-	playerStyle lipgloss.Style
+// Options control the size of the screen.
+type Options struct {
+	Width  int
+	Height int
+	Fit    bool // Size the screen to the terminal instead.
 }
 
-func initialModel() tea.Model {
-	size := vector{30, 20}
+func DefaultOptions() Options {
+	return Options{
+		Width:  30,
+		Height: 20,
+	}
+}
+
+func (o *Options) Bind(fs *flag.FlagSet) {
+	game.IntRangeVar(fs, &o.Width, "width", "width of the screen", 5, 200)
+	game.IntRangeVar(fs, &o.Height, "height", "height of the screen", 5, 100)
+	fs.BoolVar(&o.Fit, "fit", false, "size the screen to the terminal, instead of --width and --height")
+}
+
+func initialModel(rnd *rand.Rand) tea.Model {
+	return New(DefaultOptions(), rnd)
+}
+
+func New(opts Options, rnd *rand.Rand) tea.Model {
+	size := vector{opts.Width, opts.Height}
+	best, _ := scores.Best("dodger")
+	t := theme.Current()
+	k := keys.For("dodger", defaultKeys...)
+
 	return model{
-		size:        size,
-		player:      vector{int(size.x / 2), size.y - 1},
-		blocks:      []vector{},
-		score:       0,
-		blockStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("#cccccc")),
-		playerStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#aaaaff")),
+		size:    size,
+		player:  vector{int(size.x / 2), size.y - 1},
+		blocks:  []vector{},
+		score:   0,
+		best:    best,
+		rnd:     rnd,
+		theme:   t,
+		keys:    k,
+		overlay: overlay.New(t, k, description),
+		clock:   game.NewClock("fall", startInterval),
+		fit:     opts.Fit,
 	}
 }
 
+// Clocks returns the game's clock, so tests can hold it.
+func (m model) Clocks() []game.Clock {
+	return []game.Clock{m.clock}
+}
+
 func (m model) Init() tea.Cmd {
-	return nil
+	return m.clock.Init()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m.gameOver(game.Quit)
+	case overlay.Handled:
+		return m, m.overlay.Sync(m.clock)
+	}
+
+	var cmd tea.Cmd
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if m.fit {
+			// The score takes two lines above the screen, and the help
+			// footer one below it.
+			m.size = vector{
+				x: min(max(msg.Width, 5), 200),
+				y: min(max(msg.Height-3, 5), 100),
+			}
+			m.player = vector{m.size.x / 2, m.size.y - 1}
+			m.fit = false
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "left", "h":
+		switch {
+		case m.keys.Matches(msg, keys.Left):
 			m.player.x--
 			if m.player.x < 0 {
 				m.player.x = m.size.x - 1
 			}
-		case "right", "l":
+		case m.keys.Matches(msg, keys.Right):
 			m.player.x++
 			if m.player.x >= m.size.x {
 				m.player.x = 0
 			}
 		}
-	case spawnBlockMsg:
-		m.blocks = append(m.blocks, vector{rand.IntN(m.size.x), 0})
-	case moveBlockMsg:
+	case game.TickMsg:
+		if m.overlay.Open() {
+			return m, nil
+		}
+
+		m.blocks = append(m.blocks, vector{m.rnd.IntN(m.size.x), 0})
 		m.moveBlocks()
+		m.clock.Interval = max(minInterval, startInterval-time.Duration(m.score/10)*speedUp)
+		cmd = m.clock.Next()
 	}
 
 	for _, b := range m.blocks {
 		if b.x == m.player.x && b.y == m.player.y {
-			return m, tea.Quit
+			return m.gameOver(game.Lost)
 		}
 	}
 
-	return m, nil
+	return m, cmd
 }
 
 func (m model) View() string {
-	s := fmt.Sprintf("\nScore: %d\n", m.score)
+	s := "\n" + m.theme.Status.Render(fmt.Sprintf("Score: %d  best: %d", m.score, max(m.best, m.score))) + "\n"
 
 	for y := 0; y < m.size.y; y++ {
 		for x := 0; x < m.size.x; x++ {
 			drew := false
 			for _, b := range m.blocks {
 				if b.x == x && b.y == y {
-					s += m.blockStyle.Render(string(rune(0x2022))) // 0x2022 is a unicode bullet point.
+					s += m.theme.Wall.Render(string(rune(0x2022))) // 0x2022 is a unicode bullet point.
 					drew = true
 				}
 			}
 			if !drew {
 				if x == m.player.x && y == m.player.y {
-					s += m.playerStyle.Render(string(rune(0x2205))) // 0x2205 is a unicode rectangle.
+					s += m.theme.Player1.Render(string(rune(0x2205))) // 0x2205 is a unicode rectangle.
 				} else {
 					s += " "
 				}
@@ -100,9 +183,21 @@ func (m model) View() string {
 		s += "\n"
 	}
 
-	s += "hjkl or arrows to move"
+	s += m.overlay.Help()
 
-	return s
+	return m.overlay.View(s)
+}
+
+// gameOver saves the score and ends the game.
+func (m model) gameOver(outcome game.Outcome) (tea.Model, tea.Cmd) {
+	m.clock.Stop()
+	m.best, _ = scores.Record("dodger", m.score)
+
+	return m, game.Over(game.Result{
+		Outcome: outcome,
+		Score:   m.score,
+		Summary: fmt.Sprintf("score: %d  best: %d", m.score, m.best),
+	})
 }
 
 func (m *model) moveBlocks() {
@@ -120,18 +215,20 @@ func (m *model) moveBlocks() {
 	}
 }
 
-func Run() {
-	prog := tea.NewProgram(initialModel())
-
-	go func() {
-		for {
-			time.Sleep(200 * time.Millisecond)
-			prog.Send(spawnBlockMsg{})
-			prog.Send(moveBlockMsg{})
-		}
-	}()
-
-	if _, err := prog.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "dodger",
+		Name:        "dodger",
+		Players:     1,
+		Category:    game.Arcade,
+		Description: description,
+		Score:       "score",
+		New:         initialModel,
+		Ticks:       []string{"fall"},
+		Flags: func(fs *flag.FlagSet) game.Constructor {
+			opts := DefaultOptions()
+			opts.Bind(fs)
+			return func(rnd *rand.Rand) tea.Model { return New(opts, rnd) }
+		},
+	})
 }
@@ -0,0 +1,65 @@
+package hangman
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// bot plays hangman. Scores made by bots aren't recorded.
+type bot struct {
+	m model
+}
+
+type botState struct {
+	Word        string   `json:"word"`    // The word, with "_" for the letters not guessed yet.
+	Guessed     []string `json:"guessed"` // The wrong guesses.
+	GuessesLeft int      `json:"guesses_left"`
+}
+
+func newBot(rnd *rand.Rand) game.Bot {
+	return &bot{m: newWord(rnd)}
+}
+
+func (b *bot) State() any {
+	return botState{
+		Word:        string(b.m.showWord),
+		Guessed:     b.m.guessed,
+		GuessesLeft: max(b.m.guesses, 0),
+	}
+}
+
+// Moves returns the letters not tried yet.
+func (b *bot) Moves() []string {
+	var moves []string
+	for c := 'a'; c <= 'z'; c++ {
+		if !b.m.tried(string(c)) {
+			moves = append(moves, string(c))
+		}
+	}
+	return moves
+}
+
+func (b *bot) Play(move string) {
+	b.m.guess(move)
+}
+
+func (b *bot) Result() (game.Result, bool) {
+	switch {
+	case b.m.lost():
+		return game.Result{
+			Outcome: game.Lost,
+			Moves:   b.m.moves,
+			Summary: `the word was "` + b.m.word + `"`,
+		}, true
+	case b.m.won():
+		return game.Result{
+			Outcome: game.Won,
+			Score:   b.m.guesses,
+			Moves:   b.m.moves,
+			Summary: fmt.Sprintf("guesses left: %d", b.m.guesses),
+		}, true
+	}
+	return game.Result{}, false
+}
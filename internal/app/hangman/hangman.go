@@ -1,8 +1,17 @@
 package hangman
 
 import (
+	"flag"
+	"fmt"
 	"math/rand/v2"
 	"slices"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/theme"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -12,11 +21,48 @@ type model struct {
 	showWord []rune
 	guesses  int
 	guessed  []string
+	moves    int // Every letter tried, right or wrong.
 	art      []string
+	best     int  // The most guesses anyone had left after winning.
+	done     bool // Set once the game is won or lost, so it only ends once.
+	keys     keys.Map
+	theme    theme.Theme
+	overlay  overlay.Model
+}
+
+const description = "Guess the word one letter at a time before the man is hanged."
+
+// Letters are used to guess and esc to quit, so there is no key left to
+// pause with. Nothing happens in hangman until a letter is typed anyway.
+var defaultKeys = []keys.Default{
+	{Action: keys.ShowHelp, Keys: []string{"?"}, Help: "help"},
+	{Action: keys.Quit, Keys: []string{"esc"}, Help: "quit"},
+}
+
+// isLetter reports whether k is a key used to guess. Letters can't be bound
+// to anything else.
+func isLetter(k string) bool {
+	return len(k) == 1 && k[0] >= 'a' && k[0] <= 'z'
 }
 
-func initialModel() tea.Model {
-	word := wordlist[rand.IntN(len(wordlist))]
+func initialModel(rnd *rand.Rand) tea.Model {
+	m := newWord(rnd)
+
+	best, _ := scores.Best("hangman")
+	t := theme.Current()
+	k := keys.For("hangman", defaultKeys...).Except(isLetter)
+
+	m.best = best
+	m.keys = k
+	m.theme = t
+	m.overlay = overlay.New(t, k, description)
+	return m
+}
+
+// newWord returns a model with a word picked from the word list, and
+// nothing guessed yet.
+func newWord(rnd *rand.Rand) model {
+	word := wordlist[rnd.IntN(len(wordlist))]
 
 	showWord := make([]rune, len(word))
 	for i := range word {
@@ -84,44 +130,138 @@ func initialModel() tea.Model {
 	}
 }
 
+// saveVersion is the version of the saved struct's format.
+const saveVersion = 1
+
+type saved struct {
+	Word     string   `json:"word"`
+	ShowWord string   `json:"show_word"`
+	Guesses  int      `json:"guesses"`
+	Guessed  []string `json:"guessed"`
+	Moves    int      `json:"moves"`
+}
+
+func (m model) Save() (int, any) {
+	return saveVersion, saved{
+		Word:     m.word,
+		ShowWord: string(m.showWord),
+		Guesses:  m.guesses,
+		Guessed:  m.guessed,
+		Moves:    m.moves,
+	}
+}
+
+func resume(s game.Save, rnd *rand.Rand) (tea.Model, error) {
+	var st saved
+	if err := s.Decode(saveVersion, &st); err != nil {
+		return nil, err
+	}
+
+	if len(st.ShowWord) != len(st.Word) {
+		return nil, fmt.Errorf("word and guessed letters differ in length")
+	}
+	if st.Guesses < 0 || st.Guesses > 6 {
+		return nil, fmt.Errorf("invalid amount of guesses %d", st.Guesses)
+	}
+
+	m := initialModel(rnd).(model)
+	m.word = st.Word
+	m.showWord = []rune(st.ShowWord)
+	m.guesses = st.Guesses
+	m.guessed = st.Guessed
+	m.moves = st.Moves
+
+	return m, nil
+}
+
 func (m model) Init() tea.Cmd {
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m, game.Over(game.Result{Outcome: game.Quit})
+	case overlay.Handled:
+		return m, nil
+	}
+
+	if m.done {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c":
-			return m, tea.Quit
-		case "a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u", "v", "w", "x", "y", "z":
-			letter := msg.String()
-			if slices.Contains(m.guessed, letter) {
-				return m, nil
-			}
-
-			inWord := false
-			for i, char := range m.word {
-				if string(char) == letter {
-					m.showWord[i] = char
-					inWord = true
-				}
-			}
-
-			if !inWord {
-				m.guessed = append(m.guessed, letter)
-				m.guesses--
-			}
+		if letter := msg.String(); isLetter(letter) {
+			m.guess(letter)
 		}
 	}
 
-	if m.guesses <= -1 || m.word == string(m.showWord) {
-		return m, tea.Quit
+	if m.lost() {
+		m.done = true
+		return m, game.Over(game.Result{
+			Outcome: game.Lost,
+			Moves:   m.moves,
+			Summary: `the word was "` + m.word + `"`,
+		})
+	}
+
+	if m.won() {
+		m.done = true
+		// The score is the amount of wrong guesses left to spare.
+		m.best, _ = scores.Record("hangman", m.guesses)
+		return m, tea.Batch(
+			game.Publish("guessed", len(m.guessed)),
+			game.Over(game.Result{
+				Outcome: game.Won,
+				Score:   m.guesses,
+				Moves:   m.moves,
+				Summary: fmt.Sprintf("guesses left: %d  best: %d", m.guesses, m.best),
+			}),
+		)
 	}
 
 	return m, nil
 }
 
+// guess tries letter, unless it was tried already.
+func (m *model) guess(letter string) {
+	if m.tried(letter) {
+		return
+	}
+	m.moves++
+
+	inWord := false
+	for i, char := range m.word {
+		if string(char) == letter {
+			m.showWord[i] = char
+			inWord = true
+		}
+	}
+
+	if !inWord {
+		m.guessed = append(m.guessed, letter)
+		m.guesses--
+	}
+}
+
+// tried reports whether letter was guessed already, right or wrong.
+func (m model) tried(letter string) bool {
+	return slices.Contains(m.guessed, letter) || strings.ContainsRune(string(m.showWord), rune(letter[0]))
+}
+
+// lost reports whether the man was hanged.
+func (m model) lost() bool {
+	return m.guesses <= -1
+}
+
+// won reports whether every letter of the word was guessed.
+func (m model) won() bool {
+	return m.word == string(m.showWord)
+}
+
 func (m model) View() string {
 	s := ""
 
@@ -133,7 +273,7 @@ func (m model) View() string {
 
 	s += "\n\nGuessed: "
 	for _, guessed := range m.guessed {
-		s += guessed
+		s += m.theme.Error.Render(guessed)
 	}
 
 	s += "\n\nWord: "
@@ -147,13 +287,25 @@ func (m model) View() string {
 		s += `The word was "` + m.word + "\".\n\n"
 	}
 
-	return s
-}
+	s += m.theme.Status.Render(fmt.Sprintf("Guesses left: %d  best: %d", max(m.guesses, 0), m.best)) + "\n"
+	s += "\nType a letter to guess.\n" + m.overlay.Help()
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+	return m.overlay.View(s)
+}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "hangman",
+		Name:        "hangman",
+		Players:     1,
+		Category:    game.Word,
+		Description: description,
+		Score:       "guesses left",
+		New:         initialModel,
+		Resume:      resume,
+		Daily:       true,
+		Bot: func(*flag.FlagSet) game.BotConstructor {
+			return newBot
+		},
+	})
 }
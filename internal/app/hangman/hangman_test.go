@@ -0,0 +1,88 @@
+package hangman
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/gametest"
+)
+
+// start returns a game of hangman where the word to guess is word.
+func start(t *testing.T, word string) *gametest.Game {
+	g := gametest.Start(t, initialModel, 1)
+
+	m := g.Model().(model)
+	m.word = word
+	m.showWord = []rune(strings.Repeat("_", len(word)))
+	g.SetModel(m)
+	return g
+}
+
+func TestWin(t *testing.T) {
+	g := start(t, "gopher")
+
+	g.Keys("g", "o", "x", "p", "h", "e")
+	if _, over := g.Result(); over {
+		t.Fatal("the game ended before the word was guessed")
+	}
+
+	g.Keys("r")
+	r, _ := g.Result()
+	if r.Outcome != game.Won || r.Score != 5 || r.Moves != 7 {
+		t.Errorf("got %v with score %d in %d moves, want won with 5 in 7", r.Outcome, r.Score, r.Moves)
+	}
+	if e := g.Events(); len(e) != 1 || e[0].Name != "guessed" || e[0].Value != 1 {
+		t.Errorf("published %+v, want guessed with 1 wrong letter", e)
+	}
+	// The game only ends once, whatever comes after.
+	if _, cmd := g.Model().Update(gametest.Key("z")); cmd != nil {
+		t.Error("a key after the word was guessed returned a command")
+	}
+}
+
+func TestLose(t *testing.T) {
+	g := start(t, "gopher")
+
+	// Six wrong guesses draw the whole man; the seventh ends the game.
+	g.Keys("a", "b", "c", "d", "f", "i")
+	if _, over := g.Result(); over {
+		t.Fatal("the game ended with a guess to spare")
+	}
+	g.Golden("last_guess")
+
+	g.Keys("j")
+	r, _ := g.Result()
+	if r.Outcome != game.Lost || r.Summary != `the word was "gopher"` {
+		t.Errorf("got %v %q, want lost", r.Outcome, r.Summary)
+	}
+	g.Golden("lost")
+}
+
+func TestRepeat(t *testing.T) {
+	g := start(t, "gopher")
+
+	// Guessing a letter again costs nothing, right or wrong.
+	g.Keys("a", "a", "g", "g")
+	m := g.Model().(model)
+	if m.guesses != 5 || m.moves != 2 {
+		t.Errorf("got %d guesses left after %d moves, want 5 after 2", m.guesses, m.moves)
+	}
+	if string(m.showWord) != "g_____" {
+		t.Errorf("word shown as %q, want g_____", string(m.showWord))
+	}
+}
+
+func TestQuit(t *testing.T) {
+	g := start(t, "gopher")
+
+	// Letters can't quit, so q is a guess and esc asks to quit.
+	g.Keys("q", "esc", "y")
+	r, _ := g.Result()
+	if r.Outcome != game.Quit {
+		t.Errorf("esc then y gave %v, want quit", r.Outcome)
+	}
+	if m := g.Model().(model); m.guesses != 5 {
+		t.Errorf("q wasn't taken as a guess")
+	}
+}
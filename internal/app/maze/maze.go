@@ -1,7 +1,16 @@
 package maze
 
 import (
-	"github.com/Kaamkiya/gg/internal/app/maze/mazegenerator"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/theme"
+	mazegen "github.com/Kaamkiya/gg/pkg/maze"
+
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -11,13 +20,72 @@ type vector struct {
 }
 
 type model struct {
-	maze   [][]rune
-	pos    vector
-	endpos vector
+	maze    [][]rune
+	pos     vector
+	endpos  vector
+	moves   int
+	done    bool // Set once the end is reached, so the maze is only solved once.
+	keys    keys.Map
+	theme   theme.Theme
+	overlay overlay.Model
+
+	// Set if the maze is to be generated again in the size of the
+	// terminal, once that is known.
+	fit *fitting
+}
+
+type fitting struct {
+	rnd       *rand.Rand
+	algorithm string
+}
+
+const description = "Find your way from the start to the X."
+
+var defaultKeys = append(keys.Arrows(), overlay.Keys("q")...)
+
+// Options control how the maze is generated.
+type Options struct {
+	Width     int
+	Height    int
+	Algorithm string
+	Fit       bool // Size the maze to the terminal instead.
 }
 
-func initialModel() tea.Model {
-	maze := mazegenerator.GenerateMaze(25, 15, "prim")
+func DefaultOptions() Options {
+	return Options{
+		Width:     25,
+		Height:    15,
+		Algorithm: "prim",
+	}
+}
+
+func (o *Options) Bind(fs *flag.FlagSet) {
+	game.IntRangeVar(fs, &o.Width, "width", "width of the maze", 7, 201)
+	game.IntRangeVar(fs, &o.Height, "height", "height of the maze", 7, 201)
+	game.ChoiceVar(fs, &o.Algorithm, "algo", "algorithm used to generate the maze", mazegen.Algorithms...)
+	fs.BoolVar(&o.Fit, "fit", false, "size the maze to the terminal, instead of --width and --height")
+}
+
+func initialModel(rnd *rand.Rand) tea.Model {
+	return New(DefaultOptions(), rnd)
+}
+
+func New(opts Options, rnd *rand.Rand) tea.Model {
+	m := model{}.withKeys()
+	m.generate(rnd, opts)
+	if opts.Fit {
+		m.fit = &fitting{rnd: rnd, algorithm: opts.Algorithm}
+	}
+	return m
+}
+
+// generate replaces the maze with a new one.
+func (m *model) generate(rnd *rand.Rand, opts Options) {
+	maze, err := mazegen.Generate(rnd, opts.Width, opts.Height, opts.Algorithm)
+	if err != nil {
+		// The flags keep the size and the algorithm to ones that generate.
+		panic(err)
+	}
 
 	startpos := vector{}
 	endpos := vector{}
@@ -35,37 +103,129 @@ func initialModel() tea.Model {
 		}
 	}
 
-	return model{
-		maze:   maze.Grid,
-		pos:    startpos,
-		endpos: endpos,
+	m.maze = maze.Grid
+	m.pos = startpos
+	m.endpos = endpos
+	m.moves = 0
+}
+
+// withKeys sets up the theme, key bindings and overlay of m.
+func (m model) withKeys() model {
+	m.theme = theme.Current()
+	m.keys = keys.For("maze", defaultKeys...)
+	m.overlay = overlay.New(m.theme, m.keys, description)
+	return m
+}
+
+// saveVersion is the version of the saved struct's format.
+const saveVersion = 1
+
+type saved struct {
+	Maze   []string `json:"maze"`
+	Pos    [2]int   `json:"pos"`
+	EndPos [2]int   `json:"end_pos"`
+	Moves  int      `json:"moves"`
+}
+
+func (m model) Save() (int, any) {
+	rows := make([]string, len(m.maze))
+	for i, row := range m.maze {
+		rows[i] = string(row)
+	}
+
+	return saveVersion, saved{
+		Maze:   rows,
+		Pos:    [2]int{m.pos.x, m.pos.y},
+		EndPos: [2]int{m.endpos.x, m.endpos.y},
+		Moves:  m.moves,
 	}
 }
 
+func resume(s game.Save, _ *rand.Rand) (tea.Model, error) {
+	var st saved
+	if err := s.Decode(saveVersion, &st); err != nil {
+		return nil, err
+	}
+
+	maze := make([][]rune, len(st.Maze))
+	for i, row := range st.Maze {
+		maze[i] = []rune(row)
+	}
+
+	m := model{
+		maze:   maze,
+		pos:    vector{st.Pos[0], st.Pos[1]},
+		endpos: vector{st.EndPos[0], st.EndPos[1]},
+		moves:  st.Moves,
+	}.withKeys()
+
+	for _, v := range []vector{m.pos, m.endpos} {
+		if v.x <= 0 || v.x >= len(maze)-1 || v.y <= 0 || v.y >= len(maze[v.x])-1 {
+			return nil, fmt.Errorf("position %v is outside the maze", v)
+		}
+	}
+
+	return m, nil
+}
+
 func (m model) Init() tea.Cmd {
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m, game.Over(game.Result{Outcome: game.Quit})
+	case overlay.Handled:
+		return m, nil
+	}
+
+	if m.done {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if m.fit != nil {
+			// The help footer takes four lines below the maze. Mazes need
+			// an odd size, so every row and column of cells has a wall
+			// on either side.
+			w := min(max(msg.Width, 7), 201)
+			h := min(max(msg.Height-4, 7), 201)
+			m.generate(m.fit.rnd, Options{
+				Width:     w - 1 + w%2,
+				Height:    h - 1 + h%2,
+				Algorithm: m.fit.algorithm,
+			})
+			m.fit = nil
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "up", "k":
+		switch {
+		case m.keys.Matches(msg, keys.Up):
 			m.MovePlayer("up")
-		case "down", "j":
+		case m.keys.Matches(msg, keys.Down):
 			m.MovePlayer("down")
-		case "left", "h":
+		case m.keys.Matches(msg, keys.Left):
 			m.MovePlayer("left")
-		case "right", "l":
+		case m.keys.Matches(msg, keys.Right):
 			m.MovePlayer("right")
-
 		}
 	}
 
 	if m.pos == m.endpos {
-		return m, tea.Quit
+		m.done = true
+		cmds := []tea.Cmd{game.Publish("solved", m.moves)}
+		if m.moves == mazegen.ShortestPath(m.maze) {
+			cmds = append(cmds, game.Publish("solved-optimally", m.moves))
+		}
+		cmds = append(cmds, game.Over(game.Result{
+			Outcome: game.Won,
+			Moves:   m.moves,
+			Summary: fmt.Sprintf("solved in %d moves", m.moves),
+		}))
+		return m, tea.Batch(cmds...)
 	}
 
 	return m, nil
@@ -77,11 +237,11 @@ func (m model) View() string {
 	for i, row := range m.maze {
 		for j := range m.maze[i] {
 			if i == m.pos.x && j == m.pos.y {
-				s += "@"
+				s += m.theme.Player1.Render("@")
 			} else if row[j] == 'E' {
-				s += "X"
+				s += m.theme.Food.Render("X")
 			} else if row[j] == '#' {
-				s += string(rune(9608))
+				s += m.theme.Wall.Render(string(rune(9608)))
 			} else {
 				s += " "
 			}
@@ -89,12 +249,19 @@ func (m model) View() string {
 		s += "\n"
 	}
 
-	s += "\n\nhjkl or arrows to move\n"
+	s += "\n\n" + m.overlay.Help() + "\n"
 
-	return s
+	return m.overlay.View(s)
 }
 
 func (m *model) MovePlayer(dir string) {
+	prev := m.pos
+	defer func() {
+		if m.pos != prev {
+			m.moves++
+		}
+	}()
+
 	switch dir {
 	case "left":
 		m.pos.y--
@@ -119,10 +286,20 @@ func (m *model) MovePlayer(dir string) {
 	}
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "maze",
+		Name:        "maze",
+		Players:     1,
+		Category:    game.Puzzle,
+		Description: description,
+		New:         initialModel,
+		Resume:      resume,
+		Daily:       true,
+		Flags: func(fs *flag.FlagSet) game.Constructor {
+			opts := DefaultOptions()
+			opts.Bind(fs)
+			return func(rnd *rand.Rand) tea.Model { return New(opts, rnd) }
+		},
+	})
 }
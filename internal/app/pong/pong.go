@@ -1,11 +1,18 @@
 package pong
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 type vector struct {
@@ -18,26 +25,60 @@ type ballBody struct {
 	vel vector
 }
 
-type moveBallMsg struct{}
-
 type model struct {
 	hitCount int
+	best     int
 
 	size vector
 
 	paddle1 vector
 	paddle2 vector
 
-	ball ballBody
+	ball   ballBody
+	missed bool // Whether the ball got past a paddle, which ends the game.
 
-	colors []lipgloss.Style
+	seat game.Seat // Who the player is in a network game; 0 when both share the keyboard.
+
+	theme   theme.Theme
+	keys    keys.Map
+	overlay overlay.Model
+	clock   game.Clock // Moves the ball.
 }
 
+// The ball starts at one move every startInterval and gets faster with
+// every hit, up to one move every minInterval.
+const (
+	startInterval = 300 * time.Millisecond
+	minInterval   = 100 * time.Millisecond
+	speedUp       = 10 * time.Millisecond
+)
+
+const description = "Keep the ball in play. One player uses a/d, the other the arrow keys."
+
+// Each player moves their own paddle.
+const (
+	player1Left  = "player1-left"
+	player1Right = "player1-right"
+	player2Left  = "player2-left"
+	player2Right = "player2-right"
+)
+
+var defaultKeys = append([]keys.Default{
+	{Action: player1Left, Keys: []string{"a"}, Help: "player 1 left"},
+	{Action: player1Right, Keys: []string{"d"}, Help: "player 1 right"},
+	{Action: player2Left, Keys: []string{"left"}, Help: "player 2 left"},
+	{Action: player2Right, Keys: []string{"right"}, Help: "player 2 right"},
+}, overlay.Keys("q")...)
+
 func initialModel() tea.Model {
 	size := vector{30, 15}
+	best, _ := scores.Best("pong")
+	t := theme.Current()
+	k := keys.For("pong", defaultKeys...)
 
 	return model{
 		hitCount: 0,
+		best:     best,
 		size:     size,
 		paddle1:  vector{1, 8},
 		paddle2:  vector{size.x - 1, 7},
@@ -45,33 +86,85 @@ func initialModel() tea.Model {
 			pos: vector{int(15), int(8)},
 			vel: vector{1, 1},
 		},
-		colors: []lipgloss.Style{
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#aaaaff")),
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaaaa")),
-		},
+		theme:   t,
+		keys:    k,
+		overlay: overlay.New(t, k, description),
+		clock:   game.NewClock("move_ball", startInterval),
 	}
 }
 
+// Clocks returns the game's clock, so tests can hold it.
+func (m model) Clocks() []game.Clock {
+	return []game.Clock{m.clock}
+}
+
 func (m model) Init() tea.Cmd {
-	return nil
+	// The guest of a network game moves with the host's clock.
+	if m.seat == game.Guest {
+		return nil
+	}
+	return m.clock.Init()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m.gameOver(game.Quit)
+	case overlay.Handled:
+		if m.seat == game.Guest {
+			// The guest of a network game moves with the host's clock.
+			return m, nil
+		}
+		return m, m.overlay.Sync(m.clock)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "a":
+		if m.seat != 0 {
+			// Each player has a paddle of their own, moved with either
+			// set of keys.
+			switch {
+			case m.keys.Matches(msg, player1Left), m.keys.Matches(msg, player2Left):
+				return m, game.Move(m.seat, "left")
+			case m.keys.Matches(msg, player1Right), m.keys.Matches(msg, player2Right):
+				return m, game.Move(m.seat, "right")
+			}
+			return m, nil
+		}
+
+		switch {
+		case m.keys.Matches(msg, player1Left):
 			m.MovePaddle(1, -1)
-		case "d":
+		case m.keys.Matches(msg, player1Right):
 			m.MovePaddle(1, 1)
-		case "left":
+		case m.keys.Matches(msg, player2Left):
 			m.MovePaddle(2, -1)
-		case "right":
+		case m.keys.Matches(msg, player2Right):
 			m.MovePaddle(2, 1)
 		}
-	case moveBallMsg:
+	case game.MoveMsg:
+		if m.seat != game.Host {
+			return m, nil
+		}
+
+		paddle := 1
+		if msg.Seat == game.Guest {
+			paddle = 2
+		}
+
+		switch msg.Move {
+		case "left":
+			m.MovePaddle(paddle, -1)
+		case "right":
+			m.MovePaddle(paddle, 1)
+		}
+	case game.TickMsg:
+		if m.overlay.Open() {
+			return m, nil
+		}
+
 		if m.ball.pos.y < 0 || m.ball.pos.y >= m.size.y {
 			m.ball.vel.y *= -1
 		}
@@ -83,14 +176,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.ball.pos == m.paddle1 || m.ball.pos == m.paddle2 {
 			m.ball.vel.x *= -1
 			m.hitCount++
+			m.clock.Interval = max(minInterval, startInterval-time.Duration(m.hitCount)*speedUp)
 		}
 
 		if m.ball.pos.x == 0 || m.ball.pos.x >= m.size.x {
-			return m, tea.Quit
+			m.missed = true
+			return m.gameOver(game.Lost)
 		}
 
 		m.ball.pos.x += m.ball.vel.x
 		m.ball.pos.y += m.ball.vel.y
+		return m, m.clock.Next()
 	}
 	return m, nil
 }
@@ -99,28 +195,100 @@ func (m model) View() string {
 	s := ""
 
 	for i := 0; i < m.size.x; i++ {
-		s += m.colors[i%2].Render(string(rune(9608)))
+		s += m.theme.Wall.Render(string(rune(9608)))
 
 		for j := 0; j < m.size.y; j++ {
 			switch (vector{i, j}) {
 			case m.ball.pos:
-				s += "o"
+				s += m.theme.Highlight.Render("o")
 			case m.paddle1:
-				s += "-"
+				s += m.theme.Player1.Render("-")
 			case m.paddle2:
-				s += "-"
+				s += m.theme.Player2.Render("-")
 			default:
 				s += " "
 			}
 		}
 
-		s += m.colors[i%2].Render(string(rune(9608)))
+		s += m.theme.Wall.Render(string(rune(9608)))
 		s += "\n"
 	}
 
-	s += fmt.Sprintf("\nHit count: %d\n", m.hitCount)
+	s += "\n" + m.theme.Status.Render(fmt.Sprintf("Hit count: %d  best: %d", m.hitCount, max(m.best, m.hitCount))) + "\n"
+	s += "\n" + m.overlay.Help()
+
+	return m.overlay.View(s)
+}
+
+// gameOver saves the hit count and ends the game.
+func (m model) gameOver(outcome game.Outcome) (tea.Model, tea.Cmd) {
+	m.clock.Stop()
+	m.best, _ = scores.Record("pong", m.hitCount)
+
+	return m, game.Over(game.Result{
+		Outcome: outcome,
+		Score:   m.hitCount,
+		Summary: fmt.Sprintf("hit count: %d  best: %d", m.hitCount, m.best),
+	})
+}
+
+// newVersus returns a model for the player in seat of a network game. The
+// host has the first paddle.
+func newVersus(seat game.Seat) game.Versus {
+	m := initialModel().(model)
+	m.seat = seat
+	return m
+}
+
+// state is the state of a network game, sent to the guest on every tick.
+type state struct {
+	Size     [2]int `json:"size"`
+	Paddle1  [2]int `json:"paddle1"`
+	Paddle2  [2]int `json:"paddle2"`
+	Ball     [2]int `json:"ball"`
+	Velocity [2]int `json:"velocity"`
+	Hits     int    `json:"hits"`
+	Missed   bool   `json:"missed"`
+}
+
+func (v vector) pair() [2]int {
+	return [2]int{v.x, v.y}
+}
 
-	return s
+func pairVector(p [2]int) vector {
+	return vector{p[0], p[1]}
+}
+
+func (m model) State() any {
+	return state{
+		Size:     m.size.pair(),
+		Paddle1:  m.paddle1.pair(),
+		Paddle2:  m.paddle2.pair(),
+		Ball:     m.ball.pos.pair(),
+		Velocity: m.ball.vel.pair(),
+		Hits:     m.hitCount,
+		Missed:   m.missed,
+	}
+}
+
+func (m model) Sync(data json.RawMessage) (game.Versus, tea.Cmd, error) {
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return m, nil, err
+	}
+
+	m.size = pairVector(st.Size)
+	m.paddle1 = pairVector(st.Paddle1)
+	m.paddle2 = pairVector(st.Paddle2)
+	m.ball = ballBody{pos: pairVector(st.Ball), vel: pairVector(st.Velocity)}
+	m.hitCount = st.Hits
+	m.missed = st.Missed
+
+	if m.missed {
+		over, cmd := m.gameOver(game.Lost)
+		return over.(model), cmd, nil
+	}
+	return m, nil, nil
 }
 
 func (m *model) MovePaddle(num, amount int) {
@@ -137,17 +305,16 @@ func (m *model) MovePaddle(num, amount int) {
 	}
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	go func() {
-		for {
-			time.Sleep(300 * time.Millisecond)
-			p.Send(moveBallMsg{})
-		}
-	}()
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "pong",
+		Name:        "pong",
+		Players:     2,
+		Category:    game.Arcade,
+		Description: description,
+		Score:       "hit count",
+		New:         func(*rand.Rand) tea.Model { return initialModel() },
+		Ticks:       []string{"move_ball"},
+		Versus:      newVersus,
+	})
 }
@@ -1,16 +1,22 @@
 package snake
 
 import (
+	"flag"
 	"fmt"
 	"math/rand/v2"
+	"strings"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-type moveMsg struct{}
-
 type vector struct {
 	x int
 	y int
@@ -62,52 +68,118 @@ func (p player) headChar() rune {
 }
 
 type model struct {
-	foodPos   vector
-	foodStyle lipgloss.Style
-	player    player
+	rnd     *rand.Rand
+	best    int
+	size    vector
+	foodPos vector
+	theme   theme.Theme
+	player  player
+	keys    keys.Map
+	overlay overlay.Model
+	clock   game.Clock // Moves the snake.
+	fit     bool       // Whether to size the field to the terminal once its size is known.
+}
+
+// The snake starts at one move every startInterval and gets faster with
+// each piece of food, up to one move every minInterval.
+const (
+	startInterval = 200 * time.Millisecond
+	minInterval   = 80 * time.Millisecond
+	speedUp       = 5 * time.Millisecond
+)
+
+const description = "Eat the food and grow, without running into a wall or yourself."
+
+var defaultKeys = append(keys.Arrows(), overlay.Keys("q")...)
+
+// Options control the size of the playing field.
+type Options struct {
+	Width  int
+	Height int
+	Fit    bool // Size the field to the terminal instead.
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Width:  20,
+		Height: 20,
+	}
+}
+
+func (o *Options) Bind(fs *flag.FlagSet) {
+	game.IntRangeVar(fs, &o.Width, "width", "width of the playing field", 8, 200)
+	game.IntRangeVar(fs, &o.Height, "height", "height of the playing field", 8, 100)
+	fs.BoolVar(&o.Fit, "fit", false, "size the playing field to the terminal, instead of --width and --height")
 }
 
 func (m *model) setRandomFoodPos() {
 	m.foodPos = vector{
-		x: rand.IntN(20),
-		y: rand.IntN(20),
+		x: m.rnd.IntN(m.size.x),
+		y: m.rnd.IntN(m.size.y),
 	}
 }
 
+// Clocks returns the game's clock, so tests can hold it.
+func (m model) Clocks() []game.Clock {
+	return []game.Clock{m.clock}
+}
+
 func (m model) Init() tea.Cmd {
-	return nil
+	return m.clock.Init()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m.gameOver(game.Quit)
+	case overlay.Handled:
+		return m, m.overlay.Sync(m.clock)
+	}
+
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if m.fit {
+			// The border takes two columns, and two rows plus the three
+			// lines below the field.
+			m.size = vector{
+				x: min(max(msg.Width-2, 8), 200),
+				y: min(max(msg.Height-5, 8), 100),
+			}
+			m.setRandomFoodPos()
+			m.fit = false
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "k", "up":
+		switch {
+		case m.keys.Matches(msg, keys.Up):
 			if m.player.dir != dirDown {
 				m.player.dir = dirUp
 			}
-		case "j", "down":
+		case m.keys.Matches(msg, keys.Down):
 			if m.player.dir != dirUp {
 				m.player.dir = dirDown
 			}
-		case "h", "left":
+		case m.keys.Matches(msg, keys.Left):
 			if m.player.dir != dirRight {
 				m.player.dir = dirLeft
 			}
-		case "l", "right":
+		case m.keys.Matches(msg, keys.Right):
 			if m.player.dir != dirLeft {
 				m.player.dir = dirRight
 			}
 		}
-	case moveMsg:
+	case game.TickMsg:
+		if m.overlay.Open() {
+			return m, nil
+		}
+
 		m.player.move(m, m.foodPos)
 
 		head := m.player.body[0]
 
-		if head.x >= 20 || head.x < 0 || head.y < 0 || head.y >= 20 {
-			return m, tea.Quit
+		if head.x >= m.size.x || head.x < 0 || head.y < 0 || head.y >= m.size.y {
+			return m.gameOver(game.Lost)
 		}
 
 		for i, b := range m.player.body {
@@ -115,24 +187,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				continue
 			}
 			if b.equals(head) {
-				return m, tea.Quit
+				return m.gameOver(game.Lost)
 			}
 		}
 
 		if head.x == m.foodPos.x && head.y == m.foodPos.y {
 			m.setRandomFoodPos()
+			m.clock.Interval = max(minInterval, startInterval-time.Duration(m.score()-1)*speedUp)
+			return m, tea.Batch(m.clock.Next(), game.Publish("ate", m.score()))
 		}
+
+		return m, m.clock.Next()
 	}
 
 	return m, nil
 }
 
 func (m model) View() string {
-	s := "----------------------\n"
+	border := m.theme.Wall.Render(strings.Repeat("-", m.size.x+2)) + "\n"
+	s := border
 
-	for y := 0; y < 20; y++ {
-		s += "|"
-		for x := 0; x < 20; x++ {
+	for y := 0; y < m.size.y; y++ {
+		s += m.theme.Wall.Render("|")
+		for x := 0; x < m.size.x; x++ {
 			drew := false
 			for i, b := range m.player.body {
 				if b.x == x && b.y == y {
@@ -146,7 +223,7 @@ func (m model) View() string {
 			}
 			if !drew {
 				if x == m.foodPos.x && y == m.foodPos.y {
-					s += m.foodStyle.Render("0")
+					s += m.theme.Food.Render("0")
 					drew = true
 				}
 			}
@@ -154,40 +231,75 @@ func (m model) View() string {
 				s += " "
 			}
 		}
-		s += "|\n"
+		s += m.theme.Wall.Render("|") + "\n"
 	}
 
-	s += "----------------------\n"
-	s += fmt.Sprintf("Score: %d\n", len(m.player.body))
-	return s
+	s += border
+	s += m.theme.Status.Render(fmt.Sprintf("Score: %d  best: %d", m.score(), max(m.best, m.score()))) + "\n"
+	s += "\n" + m.overlay.Help()
+	return m.overlay.View(s)
 }
 
-func initialModel() tea.Model {
-	return model{
-		foodPos: vector{
-			x: rand.IntN(20),
-			y: rand.IntN(20),
-		},
-		foodStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")),
+func (m model) score() int {
+	return len(m.player.body)
+}
+
+// gameOver saves the score and ends the game.
+func (m model) gameOver(outcome game.Outcome) (tea.Model, tea.Cmd) {
+	m.clock.Stop()
+	m.best, _ = scores.Record("snake", m.score())
+
+	return m, game.Over(game.Result{
+		Outcome: outcome,
+		Score:   m.score(),
+		Summary: fmt.Sprintf("score: %d  best: %d", m.score(), m.best),
+	})
+}
+
+func initialModel(rnd *rand.Rand) tea.Model {
+	return New(DefaultOptions(), rnd)
+}
+
+func New(opts Options, rnd *rand.Rand) tea.Model {
+	best, _ := scores.Best("snake")
+	t := theme.Current()
+
+	k := keys.For("snake", defaultKeys...)
+
+	m := model{
+		rnd:     rnd,
+		best:    best,
+		size:    vector{opts.Width, opts.Height},
+		theme:   t,
+		keys:    k,
+		overlay: overlay.New(t, k, description),
+		clock:   game.NewClock("move", startInterval),
+		fit:     opts.Fit,
 		player: player{
 			body:  []vector{{6, 6}},
 			dir:   dirRight,
-			style: lipgloss.NewStyle().Foreground(lipgloss.Color("32")),
+			style: t.Player1,
 		},
 	}
-}
-
-func Run() {
-	p := tea.NewProgram(initialModel())
+	m.setRandomFoodPos()
 
-	go func() {
-		for {
-			p.Send(moveMsg{})
-			time.Sleep(200 * time.Millisecond)
-		}
-	}()
+	return m
+}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "snake",
+		Name:        "snake",
+		Players:     1,
+		Category:    game.Arcade,
+		Description: description,
+		Score:       "length",
+		New:         initialModel,
+		Ticks:       []string{"move"},
+		Flags: func(fs *flag.FlagSet) game.Constructor {
+			opts := DefaultOptions()
+			opts.Bind(fs)
+			return func(rnd *rand.Rand) tea.Model { return New(opts, rnd) }
+		},
+	})
 }
@@ -0,0 +1,102 @@
+package snake
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/gametest"
+)
+
+func TestWall(t *testing.T) {
+	g := gametest.Start(t, initialModel, 1)
+
+	// The snake starts at x 6 heading right, on a field 20 wide.
+	g.Tick("move", 13)
+	if _, over := g.Result(); over {
+		t.Fatal("the snake died before reaching the wall")
+	}
+
+	g.Tick("move", 1)
+	if r, _ := g.Result(); r.Outcome != game.Lost {
+		t.Errorf("running into the wall gave %v, want lost", r.Outcome)
+	}
+}
+
+func TestSelf(t *testing.T) {
+	g := gametest.Start(t, initialModel, 1)
+
+	// A snake long enough to run into itself by turning round:
+	//	*****>
+	m := g.Model().(model)
+	m.player.body = []vector{{10, 6}, {9, 6}, {8, 6}, {7, 6}, {6, 6}}
+	m.foodPos = vector{0, 0}
+	g.SetModel(m)
+
+	g.Keys("down").Tick("move", 1).Keys("left").Tick("move", 1)
+	if _, over := g.Result(); over {
+		t.Fatal("the snake died before turning back on itself")
+	}
+
+	g.Keys("up").Tick("move", 1)
+	if r, _ := g.Result(); r.Outcome != game.Lost {
+		t.Errorf("running into itself gave %v, want lost", r.Outcome)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	g := gametest.Start(t, initialModel, 1)
+
+	m := g.Model().(model)
+	m.player.body = []vector{{8, 6}, {7, 6}, {6, 6}}
+	g.SetModel(m)
+
+	// Turning straight back is ignored, rather than biting the neck.
+	g.Keys("left").Tick("move", 1)
+	if _, over := g.Result(); over {
+		t.Fatal("turning straight back killed the snake")
+	}
+	if head := g.Model().(model).player.body[0]; head != (vector{9, 6}) {
+		t.Errorf("head at %v after turning back, want {9 6}", head)
+	}
+}
+
+func TestEat(t *testing.T) {
+	g := gametest.Start(t, initialModel, 1)
+
+	m := g.Model().(model)
+	m.foodPos = vector{8, 6}
+	g.SetModel(m)
+
+	g.Tick("move", 2)
+	m = g.Model().(model)
+	if m.score() != 2 {
+		t.Errorf("score %d after eating, want 2", m.score())
+	}
+	if m.clock.Interval >= startInterval {
+		t.Errorf("snake didn't speed up after eating: %v", m.clock.Interval)
+	}
+	if e := g.Events(); len(e) != 1 || e[0].Name != "ate" || e[0].Value != 2 {
+		t.Errorf("published %+v after eating, want ate with 2", e)
+	}
+}
+
+func TestPaused(t *testing.T) {
+	g := gametest.Start(t, initialModel, 1)
+
+	g.Keys("p").Tick("move", 20)
+	if _, over := g.Result(); over {
+		t.Fatal("the snake moved while the game was paused")
+	}
+	g.Golden("paused")
+
+	g.Keys("p", "q", "y")
+	if r, _ := g.Result(); r.Outcome != game.Quit {
+		t.Errorf("quitting gave %v, want quit", r.Outcome)
+	}
+}
+
+func TestView(t *testing.T) {
+	g := gametest.Start(t, initialModel, 1)
+	g.Keys("down").Tick("move", 3)
+	g.Golden("moved")
+}
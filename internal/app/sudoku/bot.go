@@ -0,0 +1,91 @@
+package sudoku
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// bot plays sudoku. A move writes a number in a cell, e.g. "r3c5=7" for 7
+// in the third row and fifth column, or clears it with 0.
+type bot struct {
+	m model
+}
+
+type botState struct {
+	Grid  [][]int `json:"grid"`  // Rows from top to bottom, with 0 for empty cells.
+	Given [][]int `json:"given"` // The cells filled in from the start, which can't be changed.
+}
+
+func newBot(opts Options, rnd *rand.Rand) game.Bot {
+	return &bot{m: newPuzzle(opts, rnd)}
+}
+
+func (b *bot) State() any {
+	return botState{Grid: b.m.grid, Given: b.m.origGrid}
+}
+
+// Moves returns every number that can go in each cell that wasn't given,
+// without repeating a number in its row, column or box, and clearing the
+// cells that were filled in.
+func (b *bot) Moves() []string {
+	var moves []string
+
+	for y, row := range b.m.grid {
+		for x, cell := range row {
+			if b.m.origGrid[y][x] != 0 {
+				continue
+			}
+			if cell != 0 {
+				moves = append(moves, botMove(y, x, 0))
+			}
+			for n := 1; n <= 9; n++ {
+				if n != cell && b.fits(y, x, n) {
+					moves = append(moves, botMove(y, x, n))
+				}
+			}
+		}
+	}
+
+	return moves
+}
+
+// fits reports whether n can go in the cell at y, x without repeating a
+// number in its row, column or box.
+func (b *bot) fits(y, x, n int) bool {
+	for i := range 9 {
+		boxY, boxX := y/3*3+i/3, x/3*3+i%3
+		switch {
+		case i != x && b.m.grid[y][i] == n,
+			i != y && b.m.grid[i][x] == n,
+			(boxY != y || boxX != x) && b.m.grid[boxY][boxX] == n:
+			return false
+		}
+	}
+	return true
+}
+
+func botMove(y, x, n int) string {
+	return fmt.Sprintf("r%dc%d=%d", y+1, x+1, n)
+}
+
+func (b *bot) Play(move string) {
+	var y, x, n int
+	fmt.Sscanf(move, "r%dc%d=%d", &y, &x, &n)
+
+	b.m.cursory, b.m.cursorx = y-1, x-1
+	b.m.setSquare(fmt.Sprint(n))
+}
+
+func (b *bot) Result() (game.Result, bool) {
+	if !b.m.solved() {
+		return game.Result{}, false
+	}
+
+	return game.Result{
+		Outcome: game.Won,
+		Moves:   b.m.moves,
+		Summary: fmt.Sprintf("solved in %d moves", b.m.moves),
+	}, true
+}
@@ -1,13 +1,20 @@
 package sudoku
 
 import (
+	"flag"
 	"fmt"
+	"math/rand/v2"
+	"slices"
 	"strconv"
+	"unicode"
 
-	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/theme"
+	sudokugen "github.com/Kaamkiya/gg/pkg/sudoku"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 type model struct {
@@ -16,36 +23,75 @@ type model struct {
 
 	cursorx int
 	cursory int
+	moves   int
+
+	// mistakes counts the numbers entered that clashed with another in
+	// their row, column or box.
+	mistakes int
+
+	keys    keys.Map
+	theme   theme.Theme
+	overlay overlay.Model
 }
 
+const description = "Fill the grid so every row, column and box holds 1 to 9."
+
+// fill is the action of writing a number in the selected cell.
+const fill = "fill"
+
+var defaultKeys = slices.Concat(
+	keys.Arrows(),
+	[]keys.Default{
+		{Action: fill, Keys: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "0"}, Help: "fill, 0 clears", Label: "1-9"},
+	},
+	overlay.Keys("q"),
+)
+
 func (m model) Init() tea.Cmd {
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m, game.Over(game.Result{Outcome: game.Quit})
+	case overlay.Handled:
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "up", "k":
+		switch {
+		case m.keys.Matches(msg, keys.Up):
 			if m.cursory > 0 {
 				m.cursory--
 			}
-		case "down", "j":
+		case m.keys.Matches(msg, keys.Down):
 			if m.cursory < 8 {
 				m.cursory++
 			}
-		case "left", "h":
+		case m.keys.Matches(msg, keys.Left):
 			if m.cursorx > 0 {
 				m.cursorx--
 			}
-		case "right", "l":
+		case m.keys.Matches(msg, keys.Right):
 			if m.cursorx < 8 {
 				m.cursorx++
 			}
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9", "0":
+		case m.keys.Matches(msg, fill) && len(msg.Runes) == 1 && unicode.IsDigit(msg.Runes[0]):
 			m.setSquare(msg.String())
+			if m.solved() {
+				return m, tea.Batch(
+					game.Publish("solved", m.mistakes),
+					game.Over(game.Result{
+						Outcome: game.Won,
+						Moves:   m.moves,
+						Summary: fmt.Sprintf("solved in %d moves", m.moves),
+					}),
+				)
+			}
 		}
 	}
 
@@ -62,7 +108,7 @@ func (m model) View() string {
 			}
 
 			if j == m.cursorx && i == m.cursory {
-				col := lipgloss.NewStyle().Background(lipgloss.Color("#0000ff")).Render
+				col := m.theme.Highlight.Reverse(true).Render
 				if c == 0 {
 					s += col(" . ")
 				} else {
@@ -84,20 +130,166 @@ func (m model) View() string {
 		}
 	}
 
-	s += fmt.Sprintf("\n\norig: %v\n\ncurr: %v", m.origGrid, m.grid)
+	s += "\n" + m.overlay.Help()
 
-	return s
+	return m.overlay.View(s)
 }
 
 func (m *model) setSquare(button string) {
-	if m.origGrid[m.cursory][m.cursorx] == 0 {
-		m.grid[m.cursory][m.cursorx], _ = strconv.Atoi(button)
+	if m.origGrid[m.cursory][m.cursorx] != 0 {
+		return
+	}
+
+	n, _ := strconv.Atoi(button)
+	if m.grid[m.cursory][m.cursorx] != n {
+		m.grid[m.cursory][m.cursorx] = n
+		m.moves++
+		if m.clashes(m.cursorx, m.cursory) {
+			m.mistakes++
+		}
+	}
+}
+
+// clashes reports whether the number at x, y is also elsewhere in its row,
+// column or box.
+func (m model) clashes(x, y int) bool {
+	n := m.grid[y][x]
+	if n == 0 {
+		return false
+	}
+
+	for i := range 9 {
+		boxRow := y/3*3 + i/3
+		boxCol := x/3*3 + i%3
+
+		if (i != x && m.grid[y][i] == n) ||
+			(i != y && m.grid[i][x] == n) ||
+			((boxRow != y || boxCol != x) && m.grid[boxRow][boxCol] == n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Difficulties maps each difficulty to the amount of empty cells.
+var Difficulties = map[string]int{
+	"easy":   38,
+	"medium": 46,
+	"hard":   54,
+	"expert": 60,
+}
+
+// Options control how hard the generated puzzle is.
+type Options struct {
+	Difficulty string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Difficulty: "hard",
 	}
 }
 
-func initialModel() tea.Model {
-	g := sudokugenerator.Model{}
-	g.Init()
+func (o *Options) Bind(fs *flag.FlagSet) {
+	game.ChoiceVar(fs, &o.Difficulty, "difficulty", "how many cells start empty", "easy", "medium", "hard", "expert")
+}
+
+// solved reports whether every row, column and box holds 1 to 9.
+func (m model) solved() bool {
+	for i := range 9 {
+		var row, col, box [10]bool
+
+		for j := range 9 {
+			boxRow := i/3*3 + j/3
+			boxCol := i%3*3 + j%3
+
+			for _, n := range []int{m.grid[i][j], m.grid[j][i], m.grid[boxRow][boxCol]} {
+				if n == 0 {
+					return false
+				}
+			}
+
+			if row[m.grid[i][j]] || col[m.grid[j][i]] || box[m.grid[boxRow][boxCol]] {
+				return false
+			}
+
+			row[m.grid[i][j]] = true
+			col[m.grid[j][i]] = true
+			box[m.grid[boxRow][boxCol]] = true
+		}
+	}
+
+	return true
+}
+
+// saveVersion is the version of the saved struct's format.
+const saveVersion = 1
+
+type saved struct {
+	Orig    [][]int `json:"orig"`
+	Grid    [][]int `json:"grid"`
+	CursorX int     `json:"cursor_x"`
+	CursorY int     `json:"cursor_y"`
+	Moves   int     `json:"moves"`
+
+	Mistakes int `json:"mistakes,omitempty"`
+}
+
+func (m model) Save() (int, any) {
+	return saveVersion, saved{
+		Orig:    m.origGrid,
+		Grid:    m.grid,
+		CursorX: m.cursorx,
+		CursorY: m.cursory,
+		Moves:   m.moves,
+
+		Mistakes: m.mistakes,
+	}
+}
+
+func resume(s game.Save, _ *rand.Rand) (tea.Model, error) {
+	var st saved
+	if err := s.Decode(saveVersion, &st); err != nil {
+		return nil, err
+	}
+
+	for _, g := range [][][]int{st.Orig, st.Grid} {
+		if len(g) != 9 {
+			return nil, fmt.Errorf("grid has %d rows", len(g))
+		}
+		for _, row := range g {
+			if len(row) != 9 {
+				return nil, fmt.Errorf("grid row has %d cells", len(row))
+			}
+		}
+	}
+
+	return model{
+		origGrid: st.Orig,
+		grid:     st.Grid,
+		cursorx:  min(max(st.CursorX, 0), 8),
+		cursory:  min(max(st.CursorY, 0), 8),
+		moves:    st.Moves,
+		mistakes: st.Mistakes,
+	}.withKeys(), nil
+}
+
+func initialModel(rnd *rand.Rand) tea.Model {
+	return New(DefaultOptions(), rnd)
+}
+
+func New(opts Options, rnd *rand.Rand) tea.Model {
+	return newPuzzle(opts, rnd).withKeys()
+}
+
+// newPuzzle returns a model with a freshly generated puzzle.
+func newPuzzle(opts Options, rnd *rand.Rand) model {
+	puzzle, err := sudokugen.Generate(rnd, Difficulties[opts.Difficulty])
+	if err != nil {
+		// Every difficulty leaves between 0 and 81 cells empty.
+		panic(err)
+	}
 
 	grid := make([][]int, 9)
 	orig := make([][]int, 9)
@@ -107,8 +299,8 @@ func initialModel() tea.Model {
 		orig[i] = make([]int, 9)
 
 		for j := range 9 {
-			grid[i][j] = g.Grid[j][i]
-			orig[i][j] = g.Grid[j][i]
+			grid[i][j] = puzzle[j][i]
+			orig[i][j] = puzzle[j][i]
 		}
 	}
 
@@ -118,10 +310,33 @@ func initialModel() tea.Model {
 	}
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+// withKeys sets up the theme, key bindings and overlay of m.
+func (m model) withKeys() model {
+	m.theme = theme.Current()
+	m.keys = keys.For("sudoku", defaultKeys...)
+	m.overlay = overlay.New(m.theme, m.keys, description)
+	return m
+}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "sudoku",
+		Name:        "sudoku",
+		Players:     1,
+		Category:    game.Puzzle,
+		Description: description,
+		New:         initialModel,
+		Resume:      resume,
+		Daily:       true,
+		Flags: func(fs *flag.FlagSet) game.Constructor {
+			opts := DefaultOptions()
+			opts.Bind(fs)
+			return func(rnd *rand.Rand) tea.Model { return New(opts, rnd) }
+		},
+		Bot: func(fs *flag.FlagSet) game.BotConstructor {
+			opts := DefaultOptions()
+			opts.Bind(fs)
+			return func(rnd *rand.Rand) game.Bot { return newBot(opts, rnd) }
+		},
+	})
 }
@@ -0,0 +1,66 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// bot plays tictactoe for both players.
+type bot struct {
+	m model
+}
+
+type botState struct {
+	Board []string `json:"board"` // One string per row, top to bottom, with "." for empty cells.
+	Turn  string   `json:"turn"`
+}
+
+func newBot(*rand.Rand) game.Bot {
+	return &bot{m: emptyModel()}
+}
+
+func (b *bot) State() any {
+	var rows []string
+	for y := 0; y < 9; y += 3 {
+		row := ""
+		for _, c := range b.m.board[y : y+3] {
+			if c != 'x' && c != 'o' {
+				c = '.'
+			}
+			row += string(c)
+		}
+		rows = append(rows, row)
+	}
+
+	return botState{Board: rows, Turn: string(b.m.turn)}
+}
+
+// Moves returns the numbers of the free cells, from 1 at the top left to 9
+// at the bottom right.
+func (b *bot) Moves() []string {
+	var moves []string
+	for i, c := range b.m.board {
+		if c != 'x' && c != 'o' {
+			moves = append(moves, strconv.Itoa(i+1))
+		}
+	}
+	return moves
+}
+
+func (b *bot) Play(move string) {
+	i, _ := strconv.Atoi(move)
+	b.m.place(i - 1)
+}
+
+func (b *bot) Result() (game.Result, bool) {
+	if winner := b.m.CheckForWin(); winner != ' ' {
+		return game.Result{Outcome: game.Won, Summary: fmt.Sprintf("%c wins", winner)}, true
+	}
+	if b.m.full() {
+		return game.Result{Outcome: game.Draw}, true
+	}
+	return game.Result{}, false
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/pkg/tictactoe"
+)
+
+// bot plays a match against the AI. The client plays O and moves first.
+type bot struct {
+	board  *tictactoe.Board
+	engine *Engine
+	moves  int
+	over   bool
+	winner tictactoe.Player
+	err    error // Set if the engine failed, which ends the match.
+}
+
+type botState struct {
+	Board []string `json:"board"` // One string per row, top to bottom, with "." for empty cells.
+	You   string   `json:"you"`
+}
+
+// NewBot returns a match against an AI that runs depth MCTS iterations per
+// move, for gg serve-bot.
+func NewBot(depth int, rnd *rand.Rand) game.Bot {
+	return &bot{
+		board:  tictactoe.NewBoard(size),
+		engine: NewEngine(depth, rnd),
+	}
+}
+
+func (b *bot) State() any {
+	var rows []string
+	for y := 0; y < size; y++ {
+		row := ""
+		for x := 0; x < size; x++ {
+			cell, _ := b.board.Cell(y*size + x)
+			if sign := printPlayer(cell); sign != "" {
+				row += sign
+			} else {
+				row += "."
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return botState{Board: rows, You: printPlayer(tictactoe.P1)}
+}
+
+// Moves returns the numbers of the free cells, from 1 at the top left to 9
+// at the bottom right.
+func (b *bot) Moves() []string {
+	var moves []string
+	for _, move := range b.engine.LegalMoves(b.board) {
+		moves = append(moves, strconv.Itoa(move+1))
+	}
+	return moves
+}
+
+func (b *bot) Play(move string) {
+	index, _ := strconv.Atoi(move)
+	b.moves++
+	if b.play(tictactoe.P1, index-1) {
+		return
+	}
+
+	// The AI answers straight away, the same way it does in the game.
+	answer, err := b.engine.ai.Solve(b.board.Copy())
+	if err != nil {
+		b.fail(err)
+		return
+	}
+	b.play(tictactoe.P2, answer)
+}
+
+// play plays move for player, and reports whether it ended the match,
+// which an error from the engine does too.
+func (b *bot) play(player tictactoe.Player, move int) bool {
+	if err := b.engine.Play(b.board, player, move); err != nil {
+		b.fail(err)
+		return true
+	}
+
+	isover, win, err := b.engine.GameOver(b.board, move)
+	if err != nil {
+		b.fail(err)
+		return true
+	}
+	if isover {
+		b.over = true
+		if win == tictactoe.WinValue {
+			b.winner = player
+		}
+	}
+	return isover
+}
+
+// fail ends the match because the engine failed with err.
+func (b *bot) fail(err error) {
+	b.over = true
+	b.err = err
+}
+
+func (b *bot) Result() (game.Result, bool) {
+	if !b.over {
+		return game.Result{}, false
+	}
+	if b.err != nil {
+		return game.Result{Outcome: game.Quit, Moves: b.moves, Summary: "the AI failed: " + b.err.Error()}, true
+	}
+
+	r := game.Result{Outcome: game.Draw, Moves: b.moves}
+	switch b.winner {
+	case tictactoe.P1:
+		r.Outcome = game.Won
+	case tictactoe.P2:
+		r.Outcome = game.Lost
+	}
+	return r, true
+}
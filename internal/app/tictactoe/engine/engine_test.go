@@ -1,157 +1,143 @@
 package engine
 
 import (
+	"math/rand/v2"
+	"strings"
 	"testing"
-)
 
-var testCases = []struct {
-	input    []int
-	expected int
-}{
-	// #0: first row
-	{
-		input:    []int{1, 1, 0, -1, 0, -1, 0, 0, 0},
-		expected: 2,
-	},
-	// #1: first col
-	{
-		input:    []int{1, 0, 0, 1, -1, 0, 0, -1, 0},
-		expected: 6,
-	},
-	// #2: second col
-	{
-		input:    []int{0, 1, 0, 0, 1, -1, 0, 0, -1},
-		expected: 7,
-	},
-	// #3: diagonal left (\)
-	{
-		input:    []int{1, -1, 0, 0, 1, -1, 0, 0, 0},
-		expected: 8,
-	},
-	// #4: diagonal right (/)
-	{
-		input:    []int{0, -1, 1, 0, 1, -1, 0, 0, 0},
-		expected: 6,
-	},
-	// #5: middle row
-	{
-		input:    []int{0, 0, 0, 1, 0, 1, -1, -1, 0},
-		expected: 4,
-	},
-	// #6: last row
-	{
-		input:    []int{0, 0, 0, -1, -1, 0, 1, 1, 0},
-		expected: 8,
-	},
-	// #7: last col
-	{
-		input:    []int{0, 0, 1, -1, 0, 1, 0, 0, 0},
-		expected: 8,
-	},
-	// #8: No move
-	{
-		input:    []int{1, -1, 1, -1, -1, 1, 1, 1, -1},
-		expected: -1, // Indicates no move left to win
-	},
-}
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/gametest"
+	"github.com/Kaamkiya/gg/internal/storage"
+	"github.com/Kaamkiya/gg/pkg/tictactoe"
 
-func TestEngine_Solve(t *testing.T) {
-	BOARD_SIZE := 3
-	engine := NewEngine(DEPTH)
+	tea "github.com/charmbracelet/bubbletea"
+)
 
-	for _, tc := range testCases {
-		t.Run("Testing solve", func(t *testing.T) {
-			board := NewBoard(BOARD_SIZE)
-			board.Load(tc.input)
+func TestRecord(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Cleanup(func() { storage.SetProfile(storage.DefaultProfile) })
 
-			move := engine.ai.Solve(board)
+	for _, winner := range []tictactoe.Player{tictactoe.P1, tictactoe.P2, tictactoe.P1, 0} {
+		if _, err := addToRecord(winner); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r, _ := LoadRecord(); r != (Record{Won: 2, Lost: 1, Drawn: 1}) {
+		t.Errorf("got %+v after two wins, a loss and a draw", r)
+	}
 
-			if move != tc.expected {
-				t.Errorf("expected move %d, got %d", tc.expected, move)
-			}
-		})
+	// Another profile starts from nothing.
+	storage.SetProfile("ann")
+	if r, _ := LoadRecord(); r != (Record{}) {
+		t.Errorf("a new profile has the record %+v", r)
 	}
 }
 
-func TestEngine_CheckWin(t *testing.T) {
-	BOARD_SIZE := 3
-	board := NewBoard(BOARD_SIZE)
-	engine := NewEngine(DEPTH)
+func TestFinishedMatch(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
 
-	t.Run("Empty board", func(t *testing.T) {
-		if engine.CheckWin(board, 0) {
-			t.Error("expected no win")
-		}
+	g := GetModel(tictactoe.DefaultIterations, rand.New(rand.NewPCG(1, 2))).(Game)
+	g.board.Load([]tictactoe.Player{
+		tictactoe.P2, tictactoe.P2, tictactoe.P2,
+		tictactoe.P1, tictactoe.P1, tictactoe.Empty,
+		tictactoe.P1, tictactoe.Empty, tictactoe.Empty,
 	})
+	g.endMatch(tictactoe.P2)
 
-	t.Run("Horizontal win", func(t *testing.T) {
-		board.SetCell(0, P1)
-		board.SetCell(1, P1)
-		board.SetCell(2, P1)
-		if !engine.CheckWin(board, 2) {
-			t.Error("expected win")
-		}
-	})
+	// Nothing can be played on the board of a match that is over.
+	m, _ := g.Update(gametest.Key("6"))
+	g = m.(Game)
+	if cell, _ := g.board.Cell(5); cell != tictactoe.Empty {
+		t.Errorf("a mark was placed after the match ended")
+	}
+	if r, _ := LoadRecord(); r != (Record{Lost: 1}) {
+		t.Errorf("got the record %+v, want a single loss", r)
+	}
 
-	t.Run("Vertical win", func(t *testing.T) {
-		board = NewBoard(BOARD_SIZE)
-		board.SetCell(0, P1)
-		board.SetCell(3, P1)
-		board.SetCell(6, P1)
-		if !engine.CheckWin(board, 6) {
-			t.Error("expected win")
-		}
-	})
+	m, _ = g.Update(gametest.Key("n"))
+	g = m.(Game)
+	if g.round != 2 {
+		t.Fatalf("the next match is round %d, want 2", g.round)
+	}
 
-	t.Run("Left diagonal win", func(t *testing.T) {
-		board = NewBoard(BOARD_SIZE)
-		board.SetCell(0, P1)
-		board.SetCell(4, P1)
-		board.SetCell(8, P1)
-		if !engine.CheckWin(board, 8) {
-			t.Error("expected win")
-		}
-	})
+	// A match that isn't over can't be skipped.
+	m, _ = g.Update(gametest.Key("n"))
+	if g = m.(Game); g.round != 2 {
+		t.Errorf("a match was skipped before it ended")
+	}
+}
+
+func TestFailingEngine(t *testing.T) {
+	// An AI that runs no iterations can't choose a move.
+	g := gametest.Start(t, func(rnd *rand.Rand) tea.Model { return GetModel(0, rnd) }, 1)
+	g.Keys("5")
+
+	r, over := g.Result()
+	if !over || r.Outcome != game.Quit || !strings.HasPrefix(r.Summary, "the AI failed") {
+		t.Errorf("got %v %q, want the session to end on the AI's error", r.Outcome, r.Summary)
+	}
 
-	t.Run("Right diagonal win", func(t *testing.T) {
-		board = NewBoard(BOARD_SIZE)
-		board.SetCell(2, P1)
-		board.SetCell(4, P1)
-		board.SetCell(6, P1)
-		if !engine.CheckWin(board, 6) {
-			t.Error("expected win")
+	b := NewBot(0, rand.New(rand.NewPCG(1, 2)))
+	b.Play("5")
+	if r, over := b.Result(); !over || r.Outcome != game.Quit {
+		t.Errorf("the bot gave %v, %v, want the match to end on the AI's error", r, over)
+	}
+}
+
+// marks counts the cells of b that player has played in.
+func marks(b *tictactoe.Board, player tictactoe.Player) int {
+	n := 0
+	for i := range size * size {
+		if cell, _ := b.Cell(i); cell == player {
+			n++
 		}
-	})
+	}
+	return n
 }
 
-func TestEngine_GetLegalMoves(t *testing.T) {
-	BOARD_SIZE := 4
-	board := NewBoard(BOARD_SIZE)
-	engine := NewEngine(DEPTH)
-	moves := []int{}
+func TestAIMove(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
 
-	t.Run("Empty board", func(t *testing.T) {
-		moves = engine.GetLegalMoves(board)
-		if len(moves) != BOARD_SIZE*BOARD_SIZE {
-			t.Errorf("expected %d moves, got %d", BOARD_SIZE*BOARD_SIZE, len(moves))
-		}
-	})
+	g := GetModel(tictactoe.DefaultIterations, rand.New(rand.NewPCG(1, 2))).(Game)
+	m, _ := g.Update(gametest.Key("5"))
+	g = m.(Game)
+	m, _ = g.Update(nextTurnMsg{})
+	g = m.(Game)
 
-	t.Run("Full board", func(t *testing.T) {
-		for _, move := range moves {
-			board.SetCell(move, P1)
-		}
-		moves := engine.GetLegalMoves(board)
-		if len(moves) != 0 {
-			t.Errorf("expected 0 moves, got %d", len(moves))
-		}
-	})
+	// Choosing the move leaves the board alone; only Update plays it.
+	msg := g.aiMoveCmd()()
+	moved, ok := msg.(aiMovedMsg)
+	if !ok {
+		t.Fatalf("the AI sent %#v", msg)
+	}
+	if n := marks(g.board, tictactoe.P2); n != 0 {
+		t.Fatalf("the AI played %d times before its move reached Update", n)
+	}
 
-	t.Run("One empty cell", func(t *testing.T) {
-		board.SetCell(0, EMPTY)
-		moves = engine.GetLegalMoves(board)
-		if len(moves) != 1 {
-			t.Errorf("expected 1 move, got %d", len(moves))
-		}
-	})
+	m, _ = g.Update(moved)
+	g = m.(Game)
+	if cell, _ := g.board.Cell(moved.move); cell != tictactoe.P2 {
+		t.Errorf("the AI's move to %d wasn't played", moved.move+1)
+	}
+
+	// A move that arrives once it's no longer the AI's turn is dropped.
+	m, _ = g.Update(nextTurnMsg{})
+	g = m.(Game)
+	m, _ = g.Update(moved)
+	if n := marks(m.(Game).board, tictactoe.P2); n != 1 {
+		t.Errorf("the AI has %d marks after playing out of turn, want 1", n)
+	}
+}
+
+func TestSameSeed(t *testing.T) {
+	// The AI draws from the game's seeded source, so the same keys make the
+	// same game.
+	play := func() string {
+		g := gametest.Start(t, func(rnd *rand.Rand) tea.Model { return GetModel(tictactoe.DefaultIterations, rnd) }, 7)
+		g.Keys("5")
+		return g.Model().View()
+	}
+	if a, b := play(), play(); a != b {
+		t.Errorf("two games from the same seed differ:\n%s\n\n%s", a, b)
+	}
 }
@@ -2,64 +2,93 @@ package engine
 
 import (
 	"fmt"
-	"log"
 	"math/rand/v2"
 	"strconv"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/theme"
+	"github.com/Kaamkiya/gg/pkg/tictactoe"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type Game struct {
-	board    *Board
+	board    *tictactoe.Board
 	engine   *Engine
-	turn     Player
-	winner   Player
+	rnd      *rand.Rand
+	depth    int
+	turn     tictactoe.Player
+	winner   tictactoe.Player
 	gameover bool
 	round    int
 	scoreP1  int
 	scoreP2  int
+	best     int            // The most wins against the AI in one session.
+	matches  []game.Outcome // How each match of the session went.
+	record   Record         // Every match the profile played against the AI.
 	colors   map[string]lipgloss.Style
+	keys     keys.Map
+	overlay  overlay.Model
 }
 
-const (
-	size   = 3
-	yellow = "#FF9E3B"
-	dark   = "#3C3A32"
-	gray   = "#717C7C"
-	light  = "#DCD7BA"
-	red    = "#E63D3D"
-	green  = "#98BB6C"
-	blue   = "#7E9CD8"
-)
+const rules = "Take turns with the AI placing x and o. Three in a row wins the match; win as many matches as you can."
 
-func GetModel() tea.Model {
-	board := NewBoard(size)
-	engine := NewEngine(100)
+// place is the action of putting a mark in the cell with the number typed.
+const place = "place"
 
-	defaultStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f9f6f2"))
-	c := func(s string) lipgloss.Color {
-		return lipgloss.Color(s)
-	}
+var defaultKeys = append([]keys.Default{
+	{Action: place, Keys: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}, Help: "place", Label: "1-9"},
+	{Action: keys.Restart, Keys: []string{"n", "N"}, Help: "next match"},
+}, overlay.Keys("q")...)
+
+const size = 3
+
+// Difficulties maps each difficulty to the amount of MCTS iterations the AI
+// runs per move.
+var Difficulties = map[string]int{
+	"easy":   10,
+	"medium": tictactoe.DefaultIterations,
+	"hard":   1000,
+}
+
+func GetModel(depth int, rnd *rand.Rand) tea.Model {
+	board := tictactoe.NewBoard(size)
+	engine := NewEngine(depth, rnd)
+
+	t := theme.Current()
+
+	best, _ := scores.Best("tictactoe-ai")
+	record, _ := LoadRecord()
+	k := keys.For("tictactoe-ai", defaultKeys...)
 
 	return Game{
+		best:     best,
+		record:   record,
 		board:    board,
 		engine:   engine,
-		turn:     P1,
+		rnd:      rnd,
+		depth:    depth,
+		turn:     tictactoe.P1,
 		winner:   0,
 		round:    1,
 		scoreP1:  0,
 		scoreP2:  0,
 		gameover: false,
+		keys:     k,
+		overlay:  overlay.New(t, k, rules),
 		colors: map[string]lipgloss.Style{
-			"board":  defaultStyle.Background(c(dark)),
-			"text":   defaultStyle.Background(c(dark)).Foreground(c(light)),
-			"line":   defaultStyle.Background(c(dark)).Foreground(c(gray)),
-			"p1":     defaultStyle.Background(c(dark)).Foreground(c(yellow)),
-			"p2":     defaultStyle.Background(c(dark)).Foreground(c(red)),
-			"hi":     defaultStyle.Foreground(c(green)),
-			"status": defaultStyle.Foreground(c(blue)),
+			"board":  t.Board,
+			"text":   t.Board,
+			"line":   t.Wall.Inherit(t.Board),
+			"p1":     t.Player1.Inherit(t.Board),
+			"p2":     t.Player2.Inherit(t.Board),
+			"hi":     t.Highlight,
+			"status": t.Status,
 		},
 	}
 }
@@ -68,83 +97,79 @@ func (g Game) Init() tea.Cmd {
 	return nil
 }
 
-type gameOverMsg struct{ winner Player }
 type nextTurnMsg struct{}
 type aiTurnMsg struct{}
 
+// aiMovedMsg carries the cell the AI chose to play in.
+type aiMovedMsg struct{ move int }
+
+// failedMsg carries an error from the engine, which ends the session.
+type failedMsg struct{ err error }
+
 func (g Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := g.overlay.Update(msg)
+	g.overlay = o
+	switch res {
+	case overlay.Quit:
+		return g.quit()
+	case overlay.Handled:
+		return g, nil
+	}
+
 	switch msg := msg.(type) {
 	case aiTurnMsg:
 		time.Sleep(time.Millisecond * 200)
-		return g, aiMoveCmd(&g)
+		return g, g.aiMoveCmd()
+
+	case aiMovedMsg:
+		// The AI only thinks on its own turn, so this can't come late.
+		if g.gameover || g.turn != tictactoe.P2 {
+			break
+		}
+		return g.play(msg.move)
 
 	case nextTurnMsg:
-		g.turn = g.engine.GetOpponent(g.turn)
-		if g.turn == P2 {
+		g.turn = g.engine.Opponent(g.turn)
+		if g.turn == tictactoe.P2 {
 			return g, func() tea.Msg {
 				return aiTurnMsg{}
 			}
 		}
 		return g, nil
 
-	case gameOverMsg:
-		g.winner = msg.winner
-		g.turn = g.engine.GetOpponent(g.turn)
-		g.gameover = true
-		if g.winner == P1 {
-			g.scoreP1 += 1
-		} else if g.winner == P2 {
-			g.scoreP2 += 1
-		}
-		return g, nil
+	case failedMsg:
+		return g.fail(msg.err)
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return g, tea.Quit
-
-		case "n", "N":
+		switch {
+		case g.keys.Matches(msg, keys.Restart):
+			// A match can only be left once it is over, so a losing one
+			// can't be dropped without counting.
+			if !g.gameover {
+				break
+			}
 			g.nextMatch()
-			if g.turn == P2 {
-				return g, aiMoveCmd(&g)
+			if g.turn == tictactoe.P2 {
+				return g, g.aiMoveCmd()
 			}
 			return g, nil
 
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			// There shouldn't be an error, because this is only called for integers
-			index, _ := strconv.Atoi(msg.String())
+		case g.keys.Matches(msg, place):
+			if g.gameover || g.turn != tictactoe.P1 {
+				break
+			}
+			index, err := strconv.Atoi(msg.String())
+			if err != nil || index < 1 || index > size*size {
+				break
+			}
 			index -= 1
-			cell, err := g.board.GetCell(index)
+			cell, err := g.board.Cell(index)
 			if err != nil {
-				log.Fatal(err)
+				return g.fail(err)
 			}
 
-			if cell == EMPTY {
-				g.engine.PlayMove(g.board, P1, index)
-
-				isover, win := g.engine.CheckGameOver(g.board, index)
-
-				if isover {
-					if win > 0 {
-						g.winner = g.turn
-						// Update score
-						if g.winner == P1 {
-							g.scoreP1 += 1
-						} else if g.winner == P2 {
-							g.scoreP2 += 1
-						}
-					} else {
-						g.winner = 0
-					}
-
-					g.gameover = true
-					g.turn = g.engine.GetOpponent(g.turn)
-					return g, nil
-				}
-
-				return g, func() tea.Msg {
-					return nextTurnMsg{}
-				}
+			if cell == tictactoe.Empty {
+				return g.play(index)
 			}
 		}
 	}
@@ -152,39 +177,87 @@ func (g Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return g, nil
 }
 
-// Handle AI turn
-func aiMoveCmd(g *Game) tea.Cmd {
-	return func() tea.Msg {
-		rollout := g.board.Copy()
-		move := g.engine.ai.Solve(rollout)
-
-		g.engine.PlayMove(g.board, P2, move)
+// play puts the mark of the player whose turn it is in the cell index, then
+// ends the match or passes the turn.
+func (g Game) play(index int) (tea.Model, tea.Cmd) {
+	if err := g.engine.Play(g.board, g.turn, index); err != nil {
+		return g.fail(err)
+	}
 
-		isover, win := g.engine.CheckGameOver(g.board, move)
-		if isover {
-			if win > 0 {
-				return gameOverMsg{winner: P2}
-			}
+	isover, win, err := g.engine.GameOver(g.board, index)
+	if err != nil {
+		return g.fail(err)
+	}
 
-			return gameOverMsg{winner: 0}
+	if isover {
+		winner := tictactoe.Player(0)
+		if win == tictactoe.WinValue {
+			winner = g.turn
 		}
+		return g, g.endMatch(winner)
+	}
 
+	return g, func() tea.Msg {
 		return nextTurnMsg{}
 	}
 }
 
+// aiMoveCmd returns a command that picks the AI's move on a copy of the
+// board. The move is only played once its message reaches Update, so the
+// board never changes outside it and replays play out the same way.
+func (g Game) aiMoveCmd() tea.Cmd {
+	board, ai := g.board.Copy(), g.engine.ai
+	return func() tea.Msg {
+		move, err := ai.Solve(board)
+		if err != nil {
+			return failedMsg{err}
+		}
+		return aiMovedMsg{move}
+	}
+}
+
+// endMatch ends the match, won by winner or drawn if winner is 0, and adds
+// it to the score and the profile's record. It returns a command that
+// publishes how the match went.
+func (g *Game) endMatch(winner tictactoe.Player) tea.Cmd {
+	g.winner = winner
+	g.gameover = true
+	g.turn = g.engine.Opponent(g.turn)
+
+	event := "drew-match"
+	switch winner {
+	case tictactoe.P1:
+		g.scoreP1++
+		g.matches = append(g.matches, game.Won)
+		event = "won-match"
+	case tictactoe.P2:
+		g.scoreP2++
+		g.matches = append(g.matches, game.Lost)
+		event = "lost-match"
+	default:
+		g.matches = append(g.matches, game.Draw)
+	}
+
+	if r, err := addToRecord(winner); err == nil {
+		g.record = r
+	}
+
+	return game.Publish(event, g.scoreP1)
+}
+
 func (g *Game) nextMatch() {
-	g.board = NewBoard(size)
+	g.board = tictactoe.NewBoard(size)
 	g.gameover = false
 	g.winner = 0
 	g.round += 1
 
-	randLvl := rand.IntN(50) + 50
-	g.engine = NewEngine(randLvl)
+	// Vary the strength of the AI a little between matches.
+	randLvl := g.rnd.IntN(g.depth/2+1) + g.depth/2
+	g.engine = NewEngine(randLvl, g.rnd)
 }
 
-func printCell(board *Board, index int) string {
-	cell, err := board.GetCell(index)
+func printCell(board *tictactoe.Board, index int) string {
+	cell, err := board.Cell(index)
 	if err != nil {
 		panic(err)
 	}
@@ -198,10 +271,10 @@ func printCell(board *Board, index int) string {
 	return sign
 }
 
-func printPlayer(cell int) string {
-	if cell == P1 {
+func printPlayer(cell tictactoe.Player) string {
+	if cell == tictactoe.P1 {
 		return "O"
-	} else if cell == P2 {
+	} else if cell == tictactoe.P2 {
 		return "X"
 	}
 
@@ -210,15 +283,15 @@ func printPlayer(cell int) string {
 
 func (g Game) View() string {
 	renderCell := func(index int) string {
-		cell, _ := g.board.GetCell(index)
+		cell, _ := g.board.Cell(index)
 		var style lipgloss.Style
 		content := ""
 
 		switch cell {
-		case P1:
+		case tictactoe.P1:
 			style = g.colors["p1"]
 			content = "O"
-		case P2:
+		case tictactoe.P2:
 			style = g.colors["p2"]
 			content = "X"
 		default: // Empty cell, show index
@@ -256,12 +329,34 @@ func (g Game) View() string {
 		}
 	}
 
-	status := g.colors["status"].Render(fmt.Sprintf("\n#%d:(W%d-L%d)", g.round, g.scoreP1, g.scoreP2))
-	if g.gameover {
-		status += g.colors["status"].Render("> [Q]uit - [N]ext match")
-	} else {
+	status := g.colors["status"].Render(fmt.Sprintf("\n#%d:(W%d-L%d best:W%d)", g.round, g.scoreP1, g.scoreP2, max(g.best, g.scoreP1)))
+	if !g.gameover {
 		status += g.colors["status"].Render(fmt.Sprintf("> %s's turn", printPlayer(g.turn)))
 	}
+	status += "\n" + g.colors["status"].Render(fmt.Sprintf("all time: W%d-L%d-D%d", g.record.Won, g.record.Lost, g.record.Drawn))
+
+	return g.overlay.View(winner + board + status + "\n\n" + g.overlay.Help())
+}
+
+// fail ends the session because the engine failed with err. The matches
+// played until then still count.
+func (g Game) fail(err error) (tea.Model, tea.Cmd) {
+	g.best, _ = scores.Record("tictactoe-ai", g.scoreP1)
+	return g, game.Over(game.Result{
+		Outcome: game.Quit,
+		Score:   g.scoreP1,
+		Summary: "the AI failed: " + err.Error(),
+		Matches: g.matches,
+	})
+}
 
-	return winner + board + status
+// quit saves the amount of matches won and ends the game.
+func (g Game) quit() (tea.Model, tea.Cmd) {
+	g.best, _ = scores.Record("tictactoe-ai", g.scoreP1)
+	return g, game.Over(game.Result{
+		Outcome: game.Quit,
+		Score:   g.scoreP1,
+		Summary: fmt.Sprintf("won %d, lost %d  best: %d wins", g.scoreP1, g.scoreP2, g.best),
+		Matches: g.matches,
+	})
 }
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/storage"
+	"github.com/Kaamkiya/gg/pkg/tictactoe"
+)
+
+// Record is how every match against the AI went, kept for each profile.
+type Record struct {
+	Won   int `json:"won"`
+	Lost  int `json:"lost"`
+	Drawn int `json:"drawn"`
+}
+
+func recordPath() (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "tictactoe-ai.json"), nil
+}
+
+func readRecord(path string) (Record, error) {
+	var r Record
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+// LoadRecord returns the record of the current profile.
+func LoadRecord() (Record, error) {
+	path, err := recordPath()
+	if err != nil {
+		return Record{}, err
+	}
+
+	return readRecord(path)
+}
+
+// addToRecord adds a match won by winner, or drawn if winner is 0, to the
+// record of the current profile and returns the new record. Watching a
+// replay changes nothing.
+func addToRecord(winner tictactoe.Player) (Record, error) {
+	path, err := recordPath()
+	if err != nil {
+		return Record{}, err
+	}
+
+	unlock, err := storage.Lock(path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer unlock()
+
+	r, err := readRecord(path)
+	if err != nil || scores.ReadOnly() {
+		return r, err
+	}
+
+	switch winner {
+	case tictactoe.P1:
+		r.Won++
+	case tictactoe.P2:
+		r.Lost++
+	default:
+		r.Drawn++
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return r, err
+	}
+	return r, storage.WriteFile(path, data)
+}
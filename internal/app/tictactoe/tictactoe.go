@@ -1,24 +1,51 @@
 package tictactoe
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"math/rand/v2"
 	"strconv"
 
 	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/theme"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
-var winner = ' '
-
 type model struct {
-	turn   rune
-	board  [9]rune
-	xcolor lipgloss.Style
-	ocolor lipgloss.Style
+	turn    rune
+	board   [9]rune
+	seat    game.Seat // Who the player is in a network game; 0 when both share the keyboard.
+	theme   theme.Theme
+	keys    keys.Map
+	overlay overlay.Model
 }
 
+const description = "Take turns placing x and o. Three in a row wins."
+
+// place is the action of putting a mark in the cell with the number typed.
+const place = "place"
+
+var defaultKeys = append([]keys.Default{
+	{Action: place, Keys: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}, Help: "place", Label: "1-9"},
+}, overlay.Keys("q")...)
+
 func initialModel() tea.Model {
+	t := theme.Current()
+	k := keys.For("tictactoe", defaultKeys...)
+
+	m := emptyModel()
+	m.theme = t
+	m.keys = k
+	m.overlay = overlay.New(t, k, description)
+	return m
+}
+
+// emptyModel returns a model with an empty board, where x plays first.
+func emptyModel() model {
 	return model{
 		turn: 'x',
 		board: [9]rune{
@@ -26,55 +53,212 @@ func initialModel() tea.Model {
 			'4', '5', '6',
 			'7', '8', '9',
 		},
-		xcolor: lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")),
-		ocolor: lipgloss.NewStyle().Foreground(lipgloss.Color("#0000ff")),
 	}
 }
 
+// saveVersion is the version of the saved struct's format.
+const saveVersion = 1
+
+type saved struct {
+	Board string `json:"board"`
+	Turn  string `json:"turn"`
+}
+
+func (m model) Save() (int, any) {
+	return saveVersion, saved{
+		Board: string(m.board[:]),
+		Turn:  string(m.turn),
+	}
+}
+
+func resume(s game.Save, _ *rand.Rand) (tea.Model, error) {
+	var st saved
+	if err := s.Decode(saveVersion, &st); err != nil {
+		return nil, err
+	}
+
+	m := initialModel().(model)
+	if err := m.load(st); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// load replaces the board and turn of m with those in st.
+func (m *model) load(st saved) error {
+	cells := []rune(st.Board)
+	if len(cells) != len(m.board) {
+		return fmt.Errorf("board has %d cells", len(cells))
+	}
+	copy(m.board[:], cells)
+
+	if st.Turn != "x" && st.Turn != "o" {
+		return fmt.Errorf("invalid turn %q", st.Turn)
+	}
+	m.turn = rune(st.Turn[0])
+
+	return nil
+}
+
+// newVersus returns a model for the player in seat of a network game. The
+// host plays x and goes first.
+func newVersus(seat game.Seat) game.Versus {
+	m := initialModel().(model)
+	m.seat = seat
+	return m
+}
+
+// mark returns the mark the player in seat places.
+func mark(seat game.Seat) rune {
+	if seat == game.Guest {
+		return 'o'
+	}
+	return 'x'
+}
+
+func (m model) State() any {
+	_, st := m.Save()
+	return st
+}
+
+func (m model) Sync(state json.RawMessage) (game.Versus, tea.Cmd, error) {
+	var st saved
+	if err := json.Unmarshal(state, &st); err != nil {
+		return m, nil, err
+	}
+	if err := m.load(st); err != nil {
+		return m, nil, err
+	}
+
+	return m, m.over(), nil
+}
+
 func (m model) Init() tea.Cmd {
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m, game.Over(game.Result{Outcome: game.Quit})
+	case overlay.Handled:
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			// There shouldn't be an error, because this is only called for integers
-			position, _ := strconv.Atoi(msg.String())
-
-			if m.board[position-1] != 'x' && m.board[position-1] != 'o' {
-				m.board[position-1] = m.turn
-
-				if m.turn == 'x' {
-					m.turn = 'o'
-				} else {
-					m.turn = 'x'
-				}
+		switch {
+		case m.keys.Matches(msg, place):
+			position, err := strconv.Atoi(msg.String())
+			if err != nil || position < 1 || position > 9 {
+				break
 			}
 
-			if m.CheckForWin() != ' ' {
-				winner = m.CheckForWin()
-				return m, tea.Quit
+			if m.seat != 0 {
+				if m.turn != mark(m.seat) || m.over() != nil {
+					return m, nil
+				}
+				return m, game.Move(m.seat, msg.String())
 			}
+
+			m.place(position - 1)
+			return m, m.over()
+		}
+	case game.MoveMsg:
+		// Only the host plays moves, and only in turn.
+		position, err := strconv.Atoi(msg.Move)
+		if m.seat != game.Host || mark(msg.Seat) != m.turn || err != nil || position < 1 || position > 9 {
+			return m, nil
 		}
+
+		m.place(position - 1)
+		return m, m.over()
 	}
 
 	return m, nil
 }
 
+// over returns a command that ends the game if someone has won or the board
+// is full, or nil if it isn't over. In a network game the result is the
+// player's own.
+func (m model) over() tea.Cmd {
+	if winner := m.CheckForWin(); winner != ' ' {
+		outcome := game.Won
+		if m.seat != 0 && winner != mark(m.seat) {
+			outcome = game.Lost
+		}
+		return game.Over(game.Result{
+			Outcome: outcome,
+			Summary: fmt.Sprintf("%c wins", winner),
+		})
+	}
+
+	if m.full() {
+		return game.Over(game.Result{Outcome: game.Draw})
+	}
+
+	return nil
+}
+
+// place puts the current player's mark in cell i, if it is free, and passes
+// the turn.
+func (m *model) place(i int) {
+	if m.board[i] == 'x' || m.board[i] == 'o' {
+		return
+	}
+
+	m.board[i] = m.turn
+	if m.turn == 'x' {
+		m.turn = 'o'
+	} else {
+		m.turn = 'x'
+	}
+}
+
 func (m model) View() string {
-	s := fmt.Sprintf("%c | %c | %c\n", m.board[0], m.board[1], m.board[2])
+	cell := func(i int) string {
+		switch m.board[i] {
+		case 'x':
+			return m.theme.Player1.Render("x")
+		case 'o':
+			return m.theme.Player2.Render("o")
+		}
+		return string(m.board[i])
+	}
+
+	s := fmt.Sprintf("%s | %s | %s\n", cell(0), cell(1), cell(2))
 	s += "---------\n"
-	s += fmt.Sprintf("%c | %c | %c\n", m.board[3], m.board[4], m.board[5])
+	s += fmt.Sprintf("%s | %s | %s\n", cell(3), cell(4), cell(5))
 	s += "---------\n"
-	s += fmt.Sprintf("%c | %c | %c\n", m.board[6], m.board[7], m.board[8])
+	s += fmt.Sprintf("%s | %s | %s\n", cell(6), cell(7), cell(8))
+
+	if winner := m.CheckForWin(); winner != ' ' {
+		s += fmt.Sprintf("\n\n%c wins\n", winner)
+	} else if m.full() {
+		s += "\n\ntie!\n"
+	} else if m.seat == 0 {
+		s += fmt.Sprintf("\n\n%c's turn\n", m.turn)
+	} else if m.turn == mark(m.seat) {
+		s += fmt.Sprintf("\n\nYour turn, you are %c\n", m.turn)
+	} else {
+		s += fmt.Sprintf("\n\nWaiting for %c\n", m.turn)
+	}
+
+	s += "\n" + m.overlay.Help()
 
-	s += fmt.Sprintf("\n\n%c's turn", m.turn)
+	return m.overlay.View(s)
+}
 
-	return s
+func (m model) full() bool {
+	for _, c := range m.board {
+		if c != 'x' && c != 'o' {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (m model) CheckForWin() rune {
@@ -121,20 +305,72 @@ func (m model) CheckForWin() rune {
 	return ' '
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+// Options choose between two players and playing against the AI.
+type Options struct {
+	AI         bool
+	Difficulty string
+}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+func DefaultOptions() Options {
+	return Options{
+		AI:         false,
+		Difficulty: "medium",
 	}
-
-	fmt.Printf("%c wins\n", winner)
 }
 
-func RunVsAi() {
-	p := tea.NewProgram(engine.GetModel())
+func (o *Options) Bind(fs *flag.FlagSet) {
+	fs.BoolVar(&o.AI, "ai", o.AI, "play against the AI")
+	game.ChoiceVar(fs, &o.Difficulty, "difficulty", "strength of the AI", "easy", "medium", "hard")
+}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+func New(opts Options, rnd *rand.Rand) tea.Model {
+	if opts.AI {
+		return engine.GetModel(engine.Difficulties[opts.Difficulty], rnd)
 	}
+	return initialModel()
+}
+
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "tictactoe",
+		Name:        "tictactoe",
+		Players:     2,
+		Category:    game.Board,
+		Description: description,
+		New:         func(*rand.Rand) tea.Model { return initialModel() },
+		Resume:      resume,
+		Flags: func(fs *flag.FlagSet) game.Constructor {
+			opts := DefaultOptions()
+			opts.Bind(fs)
+			return func(rnd *rand.Rand) tea.Model { return New(opts, rnd) }
+		},
+		Bot: func(*flag.FlagSet) game.BotConstructor {
+			return newBot
+		},
+		Versus: newVersus,
+	})
+	game.Register(game.Descriptor{
+		ID:          "tictactoe-ai",
+		Name:        "tictactoe vs AI",
+		Players:     1,
+		Category:    game.Board,
+		Description: "Play tictactoe against a Monte Carlo tree search AI.",
+		Score:       "wins in one sitting",
+		New: func(rnd *rand.Rand) tea.Model {
+			return New(Options{AI: true, Difficulty: "medium"}, rnd)
+		},
+		Flags: func(fs *flag.FlagSet) game.Constructor {
+			opts := DefaultOptions()
+			opts.AI = true
+			opts.Bind(fs)
+			return func(rnd *rand.Rand) tea.Model { return New(opts, rnd) }
+		},
+		Bot: func(fs *flag.FlagSet) game.BotConstructor {
+			difficulty := "medium"
+			game.ChoiceVar(fs, &difficulty, "difficulty", "strength of the AI", "easy", "medium", "hard")
+			return func(rnd *rand.Rand) game.Bot {
+				return engine.NewBot(engine.Difficulties[difficulty], rnd)
+			}
+		},
+	})
 }
@@ -0,0 +1,65 @@
+package twenty48
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+)
+
+// botMoves maps the moves a bot can play to the directions they slide in.
+var botMoves = map[string]string{
+	"left":  keys.Left,
+	"right": keys.Right,
+	"up":    keys.Up,
+	"down":  keys.Down,
+}
+
+// bot plays 2048. Scores made by bots aren't recorded.
+type bot struct {
+	m    model
+	lost bool
+}
+
+type botState struct {
+	Grid  [4][4]int `json:"grid"` // Rows from top to bottom, with 0 for empty cells.
+	Score int       `json:"score"`
+}
+
+func newBot(rnd *rand.Rand) game.Bot {
+	b := &bot{m: model{rnd: rnd}}
+	b.m.AddTile()
+	b.m.AddTile()
+	return b
+}
+
+func (b *bot) State() any {
+	return botState{Grid: b.m.grid, Score: b.m.score}
+}
+
+func (b *bot) Moves() []string {
+	return []string{"left", "right", "up", "down"}
+}
+
+func (b *bot) Play(move string) {
+	b.lost = !b.m.move(botMoves[move])
+}
+
+func (b *bot) Result() (game.Result, bool) {
+	r := game.Result{
+		Score:   b.m.score,
+		Moves:   b.m.moves,
+		Summary: fmt.Sprintf("score: %d", b.m.score),
+	}
+
+	switch {
+	case b.lost:
+		r.Outcome = game.Lost
+	case b.m.CheckForWin():
+		r.Outcome = game.Won
+	default:
+		return game.Result{}, false
+	}
+	return r, true
+}
@@ -1,43 +1,41 @@
 package twenty48
 
 import (
+	"flag"
+	"fmt"
 	"math/rand/v2"
 	"strconv"
-	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/overlay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/theme"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 type model struct {
-	// TODO: add a score counter.
-	colors map[int]lipgloss.Style
-	grid   [4][4]int
-}
-
-func initialModel() tea.Model {
-	defaultStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f9f6f2"))
-	c := func(s string) lipgloss.Color {
-		return lipgloss.Color(s)
-	}
-
-	m := model{
-		colors: map[int]lipgloss.Style{
-			0:    defaultStyle.Background(c("#3c3a32")),
-			2:    defaultStyle.Background(c("#eee4da")).Foreground(c("#000000")),
-			4:    defaultStyle.Background(c("#ede0c8")).Foreground(c("#000000")),
-			8:    defaultStyle.Background(c("#f2b179")),
-			16:   defaultStyle.Background(c("#f59563")),
-			32:   defaultStyle.Background(c("#f67c5f")),
-			64:   defaultStyle.Background(c("#f65e3b")),
-			128:  defaultStyle.Background(c("#edcf72")),
-			256:  defaultStyle.Background(c("#edcc61")),
-			512:  defaultStyle.Background(c("#edc850")),
-			1024: defaultStyle.Background(c("#edc53f")),
-			2048: defaultStyle.Background(c("#edc22e")),
-		},
-		grid: [4][4]int{},
-	}
+	rnd     *rand.Rand
+	theme   theme.Theme
+	grid    [4][4]int
+	score   int // The sum of every tile created by a merge.
+	moves   int
+	best    int
+	done    bool // Set once the game is won or lost, so it only ends once.
+	keys    keys.Map
+	overlay overlay.Model
+
+	// merged is the biggest tile the last move made by merging two, or 0.
+	merged int
+}
+
+const description = "Slide the tiles and merge equal numbers to reach 2048."
+
+var defaultKeys = append(keys.Arrows(), overlay.Keys("q")...)
+
+func initialModel(rnd *rand.Rand) tea.Model {
+	m := emptyModel(rnd)
 
 	// The board needs to start with two starting tiles.
 	m.AddTile()
@@ -45,70 +43,95 @@ func initialModel() tea.Model {
 	return m
 }
 
+// emptyModel returns a model with an empty grid.
+func emptyModel(rnd *rand.Rand) model {
+	best, _ := scores.Best("twenty48")
+	t := theme.Current()
+	k := keys.For("twenty48", defaultKeys...)
+
+	return model{
+		rnd:     rnd,
+		best:    best,
+		keys:    k,
+		theme:   t,
+		overlay: overlay.New(t, k, description),
+		grid:    [4][4]int{},
+	}
+}
+
+// saveVersion is the version of the saved struct's format.
+const saveVersion = 1
+
+type saved struct {
+	Grid  [4][4]int `json:"grid"`
+	Score int       `json:"score"`
+	Moves int       `json:"moves"`
+}
+
+func (m model) Save() (int, any) {
+	return saveVersion, saved{
+		Grid:  m.grid,
+		Score: m.score,
+		Moves: m.moves,
+	}
+}
+
+func resume(s game.Save, rnd *rand.Rand) (tea.Model, error) {
+	var st saved
+	if err := s.Decode(saveVersion, &st); err != nil {
+		return nil, err
+	}
+
+	m := emptyModel(rnd)
+	m.grid = st.Grid
+	m.score = st.Score
+	m.moves = st.Moves
+	return m, nil
+}
+
 func (m model) Init() tea.Cmd {
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	o, res := m.overlay.Update(msg)
+	m.overlay = o
+	switch res {
+	case overlay.Quit:
+		return m.gameOver(game.Quit)
+	case overlay.Handled:
+		return m, nil
+	}
+
+	if m.done {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "left", "h":
-			m.MergeTilesLeft()
-			/* NOTE: There is an edge case here. This code requires
-			 * that every move the user makes must free up a tile.
-			 * This means that even if the board looks like this:
-			 * 2 | 4 | 8 | 16
-			 * 2 | 4 | 8 | 16
-			 * 2 | 4 | 8 | 16
-			 * 2 | 4 | 8 | 16
-			 * and there is still technically a possible move, if
-			 * the player does not open up new space, the game is
-			 * over.
-			 */
-			// TODO: Fix above.
-			if !m.AddTile() {
-				return m, tea.Quit
-			}
-		case "down", "j":
-			/* Instead of creating a separate method to merge down,
-			 * we rotate the grid. This is because the
-			 * m.MergeTilesLeft() method is *much* more complex
-			 * than m.Rotate90(), so it's simpler to rotate, merge,
-			 * then rotate back than to create a separate function.
-			 */
-			m.Rotate90(false)
-			m.MergeTilesLeft()
-			m.Rotate90(true)
-			if !m.AddTile() {
-				return m, tea.Quit
+		for _, action := range []string{keys.Left, keys.Down, keys.Up, keys.Right} {
+			if !m.keys.Matches(msg, action) {
+				continue
 			}
-		case "up", "k":
-			m.Rotate90(true)
-			m.MergeTilesLeft()
-			m.Rotate90(false)
-			if !m.AddTile() {
-				return m, tea.Quit
-			}
-		case "right", "l":
-			m.Rotate90(false)
-			m.Rotate90(false)
-			m.MergeTilesLeft()
-			m.Rotate90(true)
-			m.Rotate90(true)
-			if !m.AddTile() {
-				return m, tea.Quit
+			if !m.move(action) {
+				return m.gameOver(game.Lost)
 			}
+			break
 		}
 	}
 
+	var merged tea.Cmd
+	if m.merged > 0 {
+		merged = game.Publish("merged", m.merged)
+		m.merged = 0
+	}
+
 	if m.CheckForWin() {
-		return m, tea.Quit
+		won, cmd := m.gameOver(game.Won)
+		return won, tea.Batch(merged, cmd)
 	}
 
-	return m, nil
+	return m, merged
 }
 
 func (m model) View() string {
@@ -125,7 +148,7 @@ func (m model) View() string {
 			 * For that reason, we add empty spaces. It provides a
 			 * row of padding, so the game looks better.
 			 */
-			s += m.colors[m.grid[y][x]].Render("      ")
+			s += m.theme.Tile(m.grid[y][x]).Render("      ")
 		}
 		s += "\n"
 		for x := 0; x < 4; x++ {
@@ -138,21 +161,83 @@ func (m model) View() string {
 			 * the tiles is even.
 			 */
 			for i := 0; i < 5-len(stringifiedNum); i++ {
-				s += m.colors[m.grid[y][x]].Render(" ")
+				s += m.theme.Tile(m.grid[y][x]).Render(" ")
 			}
-			s += m.colors[m.grid[y][x]].Render(stringifiedNum + " ")
+			s += m.theme.Tile(m.grid[y][x]).Render(stringifiedNum + " ")
 		}
 		s += "\n"
 		for x := 0; x < 4; x++ {
 			// This is for the bottom line of padding.
-			s += m.colors[m.grid[y][x]].Render("      ")
+			s += m.theme.Tile(m.grid[y][x]).Render("      ")
 		}
 		s += "\n"
 	}
 
-	s += "\nhjkl or arrows to move"
+	s += "\n" + m.theme.Status.Render(fmt.Sprintf("Score: %d  best: %d", m.score, max(m.best, m.score))) + "\n"
+	s += "\n" + m.overlay.Help()
 
-	return s
+	return m.overlay.View(s)
+}
+
+// move slides the tiles in the direction of action, one of keys.Left,
+// keys.Down, keys.Up and keys.Right, and adds a new tile. It returns false
+// if there is no room left for the tile, which loses the game.
+func (m *model) move(action string) bool {
+	m.moves++
+
+	/* Instead of creating a separate method to merge in every
+	 * direction, we rotate the grid. This is because the
+	 * m.MergeTilesLeft() method is *much* more complex than
+	 * m.Rotate90(), so it's simpler to rotate, merge, then rotate
+	 * back than to create a separate function.
+	 */
+	switch action {
+	case keys.Left:
+		m.MergeTilesLeft()
+	case keys.Down:
+		m.Rotate90(false)
+		m.MergeTilesLeft()
+		m.Rotate90(true)
+	case keys.Up:
+		m.Rotate90(true)
+		m.MergeTilesLeft()
+		m.Rotate90(false)
+	case keys.Right:
+		m.Rotate90(false)
+		m.Rotate90(false)
+		m.MergeTilesLeft()
+		m.Rotate90(true)
+		m.Rotate90(true)
+	}
+
+	/* NOTE: There is an edge case here. This code requires that
+	 * every move the user makes must free up a tile. This means
+	 * that even if the board looks like this:
+	 * 2 | 4 | 8 | 16
+	 * 2 | 4 | 8 | 16
+	 * 2 | 4 | 8 | 16
+	 * 2 | 4 | 8 | 16
+	 * and there is still technically a possible move, if the
+	 * player does not open up new space, the game is over.
+	 */
+	// TODO: Fix above.
+	return m.AddTile()
+}
+
+// gameOver saves the score and ends the game.
+func (m model) gameOver(outcome game.Outcome) (tea.Model, tea.Cmd) {
+	// A game that was quit is saved to be resumed, so it isn't over yet.
+	if outcome != game.Quit {
+		m.done = true
+		m.best, _ = scores.Record("twenty48", m.score)
+	}
+
+	return m, game.Over(game.Result{
+		Outcome: outcome,
+		Score:   m.score,
+		Moves:   m.moves,
+		Summary: fmt.Sprintf("score: %d  best: %d", m.score, m.best),
+	})
 }
 
 func (m *model) MergeTilesLeft() {
@@ -168,6 +253,8 @@ func (m *model) MergeTilesLeft() {
 					case m.grid[i][k-1] == m.grid[i][k]:
 						m.grid[i][k-1] += m.grid[i][k]
 						m.grid[i][k] = 0
+						m.score += m.grid[i][k-1]
+						m.merged = max(m.merged, m.grid[i][k-1])
 						stopMerge = k
 					default:
 						break
@@ -192,15 +279,9 @@ func (m *model) AddTile() bool {
 		return false
 	}
 
-	rndSrc := rand.NewPCG(
-		uint64(time.Now().UnixNano()),
-		uint64(time.Now().UnixMilli()),
-	)
-	rnd := rand.New(rndSrc)
-
-	cell := empty[rnd.IntN(len(empty))]
+	cell := empty[m.rnd.IntN(len(empty))]
 
-	if rnd.IntN(10) < 9 {
+	if m.rnd.IntN(10) < 9 {
 		m.grid[cell/len(m.grid)][cell%len(m.grid)] = 2
 	} else {
 		m.grid[cell/len(m.grid)][cell%len(m.grid)] = 4
@@ -236,10 +317,19 @@ func (m model) CheckForWin() bool {
 	return false
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Descriptor{
+		ID:          "twenty48",
+		Name:        "2048",
+		Players:     1,
+		Category:    game.Puzzle,
+		Description: description,
+		Score:       "score",
+		New:         initialModel,
+		Resume:      resume,
+		Daily:       true,
+		Bot: func(*flag.FlagSet) game.BotConstructor {
+			return newBot
+		},
+	})
 }
@@ -0,0 +1,132 @@
+package twenty48
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/gametest"
+	"github.com/Kaamkiya/gg/internal/scores"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// start returns a game of 2048 with grid on the board.
+func start(t *testing.T, grid [4][4]int) *gametest.Game {
+	g := gametest.Start(t, initialModel, 1)
+
+	m := g.Model().(model)
+	m.grid = grid
+	g.SetModel(m)
+	return g
+}
+
+// checkGrid fails the test unless got is want plus the one tile added after
+// every move.
+func checkGrid(t *testing.T, got, want [4][4]int) {
+	t.Helper()
+
+	added := 0
+	for y := range want {
+		for x := range want[y] {
+			switch {
+			case want[y][x] != 0 && got[y][x] != want[y][x]:
+				t.Fatalf("got grid %v, want %v", got, want)
+			case want[y][x] == 0 && got[y][x] != 0:
+				added++
+			}
+		}
+	}
+
+	if added != 1 {
+		t.Errorf("%d tiles were added to %v, want 1", added, got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		row   [4]int
+		want  [4]int
+		score int
+	}{
+		{"slide", "left", [4]int{0, 0, 0, 2}, [4]int{2, 0, 0, 0}, 0},
+		{"pair", "left", [4]int{2, 0, 0, 2}, [4]int{4, 0, 0, 0}, 4},
+		{"two pairs", "left", [4]int{2, 2, 2, 2}, [4]int{4, 4, 0, 0}, 8},
+		{"merged tiles don't merge again", "left", [4]int{2, 2, 4, 0}, [4]int{4, 4, 0, 0}, 4},
+		{"nearest pair first", "left", [4]int{4, 4, 4, 0}, [4]int{8, 4, 0, 0}, 8},
+		{"different tiles", "left", [4]int{2, 4, 2, 4}, [4]int{2, 4, 2, 4}, 0},
+		{"right", "right", [4]int{2, 2, 2, 0}, [4]int{0, 0, 2, 4}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := start(t, [4][4]int{tt.row})
+			g.Keys(tt.key)
+
+			m := g.Model().(model)
+			checkGrid(t, m.grid, [4][4]int{tt.want})
+			if m.score != tt.score {
+				t.Errorf("score %d, want %d", m.score, tt.score)
+			}
+		})
+	}
+}
+
+func TestMergeColumns(t *testing.T) {
+	grid := [4][4]int{
+		{2, 0, 0, 0},
+		{2, 0, 0, 0},
+		{4, 0, 0, 0},
+		{4, 0, 0, 0},
+	}
+
+	g := start(t, grid)
+	g.Keys("up")
+	checkGrid(t, g.Model().(model).grid, [4][4]int{{4}, {8}})
+
+	g = start(t, grid)
+	g.Keys("down")
+	checkGrid(t, g.Model().(model).grid, [4][4]int{{}, {}, {4}, {8}})
+}
+
+func TestWin(t *testing.T) {
+	g := start(t, [4][4]int{{1024, 1024}})
+	g.Keys("left")
+
+	r, _ := g.Result()
+	if r.Outcome != game.Won || r.Score != 2048 {
+		t.Errorf("merging 2048 gave %v with score %d, want won with 2048", r.Outcome, r.Score)
+	}
+	if e := g.Events(); len(e) != 1 || e[0].Name != "merged" || e[0].Value != 2048 {
+		t.Errorf("published %+v, want merged with 2048", e)
+	}
+	// The game only ends once, whatever comes after.
+	m := g.Model()
+	for _, k := range []string{"left", "right"} {
+		var cmd tea.Cmd
+		if m, cmd = m.Update(gametest.Key(k)); cmd != nil {
+			t.Errorf("%s after the game was won returned a command", k)
+		}
+	}
+	if s, err := scores.Default(); err != nil {
+		t.Fatal(err)
+	} else if top, _ := s.Top("twenty48"); len(top) != 1 {
+		t.Errorf("%d scores were recorded, want 1", len(top))
+	}
+}
+
+func TestLose(t *testing.T) {
+	g := start(t, [4][4]int{
+		{2, 4, 2, 4},
+		{4, 2, 4, 2},
+		{2, 4, 2, 4},
+		{4, 2, 4, 2},
+	})
+	g.Keys("left")
+
+	if r, _ := g.Result(); r.Outcome != game.Lost {
+		t.Errorf("a full board gave %v, want lost", r.Outcome)
+	}
+	g.Golden("lost")
+}
@@ -0,0 +1,104 @@
+// Package bot serves a game to a program over JSON lines, so scripts and
+// agents can play without the terminal interface.
+//
+// Before every move the server writes a Message with the position and the
+// moves that can be played. The client answers with a Move on a line of its
+// own:
+//
+//	{"game":"connect4","state":{...},"moves":["1","2","3","4","5","6","7"]}
+//	{"move":"4"}
+//	{"game":"connect4","state":{...},"moves":[...],"last":{"move":"4"}}
+//
+// A move that can't be played is answered with the same position and an
+// error in last. Once the game is over, the last message has over set and
+// the result, and the server stops.
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// Message is what the server sends before every move, and once the game is
+// over.
+type Message struct {
+	Game   string   `json:"game"`
+	State  any      `json:"state"`
+	Moves  []string `json:"moves"`
+	Last   *Last    `json:"last,omitempty"`
+	Over   bool     `json:"over"`
+	Result *Result  `json:"result,omitempty"`
+}
+
+// Last is the outcome of the client's last move.
+type Last struct {
+	Move  string `json:"move"`
+	Error string `json:"error,omitempty"` // Why the move wasn't played, if it wasn't.
+}
+
+// Result is how the game ended.
+type Result struct {
+	Outcome string `json:"outcome"`
+	Score   int    `json:"score"`
+	Moves   int    `json:"moves"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// Move is what the client sends.
+type Move struct {
+	Move string `json:"move"`
+}
+
+// Serve plays b, a game of d, with the client reading from r and writing
+// to w. It returns nil once the game is over or the client closes r.
+func Serve(d game.Descriptor, b game.Bot, r io.Reader, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	lines := bufio.NewScanner(r)
+	var last *Last
+
+	for {
+		msg := Message{Game: d.ID, State: b.State(), Moves: b.Moves(), Last: last}
+		if msg.Moves == nil {
+			msg.Moves = []string{}
+		}
+
+		if res, over := b.Result(); over {
+			msg.Over = true
+			msg.Moves = []string{}
+			msg.Result = &Result{
+				Outcome: res.Outcome.String(),
+				Score:   res.Score,
+				Moves:   res.Moves,
+				Summary: res.Summary,
+			}
+			return enc.Encode(msg)
+		}
+
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+
+		if !lines.Scan() {
+			return lines.Err()
+		}
+
+		var move Move
+		if err := json.Unmarshal(lines.Bytes(), &move); err != nil {
+			last = &Last{Error: fmt.Sprintf(`want {"move": "..."}: %v`, err)}
+			continue
+		}
+
+		last = &Last{Move: move.Move}
+		if !slices.Contains(msg.Moves, move.Move) {
+			last.Error = "not one of the moves"
+			continue
+		}
+
+		b.Play(move.Move)
+	}
+}
@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// countdown is a game where the only move takes one off the count, which is
+// won at zero.
+type countdown struct {
+	n int
+}
+
+func (c *countdown) State() any      { return map[string]int{"n": c.n} }
+func (c *countdown) Moves() []string { return []string{strconv.Itoa(c.n)} }
+func (c *countdown) Play(string)     { c.n-- }
+
+func (c *countdown) Result() (game.Result, bool) {
+	return game.Result{Outcome: game.Won, Moves: 2}, c.n == 0
+}
+
+func serve(t *testing.T, input string) []Message {
+	t.Helper()
+
+	var out strings.Builder
+	d := game.Descriptor{ID: "countdown"}
+	if err := Serve(d, &countdown{n: 2}, strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []Message
+	lines := bufio.NewScanner(strings.NewReader(out.String()))
+	for lines.Scan() {
+		var msg Message
+		if err := json.Unmarshal(lines.Bytes(), &msg); err != nil {
+			t.Fatalf("%q: %v", lines.Text(), err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestServe(t *testing.T) {
+	msgs := serve(t, `{"move":"2"}`+"\n"+`{"move":"5"}`+"\n"+"move 1\n"+`{"move":"1"}`+"\n")
+
+	if len(msgs) != 5 {
+		t.Fatalf("got %d messages, want 5: %+v", len(msgs), msgs)
+	}
+
+	first := msgs[0]
+	if first.Game != "countdown" || first.Last != nil || first.Over || len(first.Moves) != 1 || first.Moves[0] != "2" {
+		t.Errorf("first message %+v", first)
+	}
+
+	if last := msgs[1].Last; last == nil || last.Move != "2" || last.Error != "" {
+		t.Errorf("a legal move was answered with %+v", last)
+	}
+	if last := msgs[2].Last; last == nil || last.Error == "" || msgs[2].Moves[0] != "1" {
+		t.Errorf("an illegal move was answered with %+v", msgs[2])
+	}
+	if last := msgs[3].Last; last == nil || last.Error == "" {
+		t.Errorf("a line that isn't JSON was answered with %+v", msgs[3])
+	}
+
+	end := msgs[4]
+	if !end.Over || end.Result == nil || end.Result.Outcome != "won" || len(end.Moves) != 0 {
+		t.Errorf("last message %+v", end)
+	}
+}
+
+func TestClientLeaves(t *testing.T) {
+	msgs := serve(t, `{"move":"2"}`+"\n")
+
+	if len(msgs) != 2 || msgs[1].Over {
+		t.Errorf("got %+v, want two messages and the game not over", msgs)
+	}
+}
@@ -0,0 +1,152 @@
+// Package cast records what a program draws as an asciinema v2 cast, which
+// can be played with asciinema or turned into a gif for the README.
+package cast
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The size used if the program never learns the terminal's size.
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+type frame struct {
+	at   time.Duration
+	data string
+}
+
+// Recorder wraps a model and keeps every distinct frame it renders. It is
+// a tea.Model itself, so it can be run in place of the model it wraps.
+type Recorder struct {
+	model tea.Model
+	rec   *recording // Shared between the copies bubbletea makes.
+}
+
+type recording struct {
+	started time.Time
+	width   int
+	height  int
+	last    string
+	frames  []frame
+}
+
+// NewRecorder returns a recorder for model. Call Write once the program
+// has finished to store the cast.
+func NewRecorder(model tea.Model) Recorder {
+	return Recorder{
+		model: model,
+		rec:   &recording{started: time.Now()},
+	}
+}
+
+func (r Recorder) Init() tea.Cmd {
+	return r.model.Init()
+}
+
+func (r Recorder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		// A cast has a single size, so the largest one seen is used.
+		r.rec.width = max(r.rec.width, msg.Width)
+		r.rec.height = max(r.rec.height, msg.Height)
+	}
+
+	var cmd tea.Cmd
+	r.model, cmd = r.model.Update(msg)
+	return r, cmd
+}
+
+func (r Recorder) View() string {
+	view := r.model.View()
+
+	if view != r.rec.last {
+		r.rec.last = view
+		r.rec.frames = append(r.rec.frames, frame{
+			at:   time.Since(r.rec.started),
+			data: redraw(view),
+		})
+	}
+
+	return view
+}
+
+// redraw returns the output that draws view over the previous frame.
+func redraw(view string) string {
+	var b strings.Builder
+
+	b.WriteString("\x1b[H")
+	for i, line := range strings.Split(view, "\n") {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+
+	return b.String()
+}
+
+// Write stores the cast at path.
+func (r Recorder) Write(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	width, height := r.rec.width, r.rec.height
+	if width == 0 || height == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+
+	err = enc.Encode(header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.rec.started.Unix(),
+		Env: map[string]string{
+			"SHELL": os.Getenv("SHELL"),
+			"TERM":  os.Getenv("TERM"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Hide the cursor, as the program did.
+	if err := enc.Encode([]any{0.0, "o", "\x1b[?25l\x1b[2J"}); err != nil {
+		return err
+	}
+
+	for _, fr := range r.rec.frames {
+		if err := enc.Encode([]any{fr.at.Seconds(), "o", fr.data}); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
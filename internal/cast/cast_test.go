@@ -0,0 +1,67 @@
+package cast
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type counter int
+
+func (c counter) Init() tea.Cmd                       { return nil }
+func (c counter) Update(tea.Msg) (tea.Model, tea.Cmd) { return c + 1, nil }
+func (c counter) View() string                        { return strings.Repeat("#", int(c)) + "\nline two" }
+
+func TestWrite(t *testing.T) {
+	var m tea.Model = NewRecorder(counter(1))
+	m.View()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 40, Height: 10})
+	m.View()
+	m.View() // An unchanged frame isn't recorded again.
+
+	path := filepath.Join(t.TempDir(), "out.cast")
+	if err := m.(Recorder).Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Scan()
+	var h header
+	if err := json.Unmarshal(s.Bytes(), &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Version != 2 || h.Width != 40 || h.Height != 10 {
+		t.Errorf("unexpected header %+v", h)
+	}
+
+	var frames []string
+	for s.Scan() {
+		var event []any
+		if err := json.Unmarshal(s.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		if len(event) != 3 || event[1] != "o" {
+			t.Fatalf("unexpected event %v", event)
+		}
+		frames = append(frames, event[2].(string))
+	}
+
+	// The first event only clears the screen.
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(frames))
+	}
+	if !strings.Contains(frames[2], "##\x1b[K\r\nline two") {
+		t.Errorf("expected the second frame to be drawn, got %q", frames[2])
+	}
+}
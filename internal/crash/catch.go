@@ -0,0 +1,165 @@
+package crash
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Error is what a program ends with once gg panicked.
+type Error struct {
+	Panic  any
+	Report string // Where the crash report was written.
+	Err    error  // Why it couldn't be written, if it couldn't.
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("gg crashed (%v), and the crash report couldn't be written: %v", e.Panic, e.Err)
+	}
+	return fmt.Sprintf("gg crashed (%v). A crash report was written to %s", e.Panic, e.Report)
+}
+
+// Save writes r and returns the error to end the program with.
+func Save(r Report, p any) *Error {
+	path, err := Write(r)
+	return &Error{Panic: p, Report: path, Err: err}
+}
+
+// PanicMsg carries a panic recovered while a command ran.
+type PanicMsg struct {
+	Value any
+	Stack []byte
+}
+
+// Guard returns a command that runs cmd, and the commands it batches, and
+// sends a PanicMsg if one of them panics. Bubbletea runs commands on their
+// own goroutines, where a panic would end the program without restoring the
+// terminal.
+func Guard(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return func() (msg tea.Msg) {
+		defer func() {
+			if p := recover(); p != nil {
+				msg = PanicMsg{Value: p, Stack: debug.Stack()}
+			}
+		}()
+
+		msg = cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for i := range batch {
+				batch[i] = Guard(batch[i])
+			}
+		}
+		return msg
+	}
+}
+
+// Describer is implemented by models that know what a crash report should
+// say about them, like the game being played and how it was started.
+type Describer interface {
+	Describe(r *Report)
+}
+
+// Catcher runs a model, and writes a crash report and quits if the model
+// panics in Update, View or one of its commands. Quitting leaves the
+// terminal the way it was.
+type Catcher struct {
+	model tea.Model
+	state *caught // Shared by the copies of the catcher.
+}
+
+// caught is the crash a Catcher caught, if it caught one.
+type caught struct {
+	err *Error
+
+	// drawing is set if the crash happened while the model was drawn, so
+	// the program only quits at the next message.
+	drawing bool
+}
+
+// Catch returns a Catcher running m.
+func Catch(m tea.Model) Catcher {
+	return Catcher{model: m, state: &caught{}}
+}
+
+// Err returns an *Error if the program c ran in ended because the model
+// panicked, and nil otherwise.
+func (c Catcher) Err() error {
+	if c.state.err == nil {
+		return nil
+	}
+	return c.state.err
+}
+
+func (c Catcher) Init() (cmd tea.Cmd) {
+	defer func() {
+		if p := recover(); p != nil {
+			_, cmd = c.crashed(p, debug.Stack())
+		}
+	}()
+
+	return Guard(c.model.Init())
+}
+
+func (c Catcher) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
+	if c.state.err != nil {
+		// The model crashed while it was drawn.
+		return c, tea.Quit
+	}
+	if msg, ok := msg.(PanicMsg); ok {
+		return c.crashed(msg.Value, msg.Stack)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			model, cmd = c.crashed(p, debug.Stack())
+		}
+	}()
+
+	c.model, cmd = c.model.Update(msg)
+	return c, Guard(cmd)
+}
+
+// View draws the model. If it panics, the screen is replaced until the
+// program quits at the next message.
+func (c Catcher) View() (view string) {
+	if c.state.err != nil {
+		return c.crashView()
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			c.crashed(p, debug.Stack())
+			c.state.drawing = true
+			view = c.crashView()
+		}
+	}()
+
+	return c.model.View()
+}
+
+// crashView replaces the screen once the model crashed. The error is
+// printed once the program quits, so the screen is left empty unless the
+// program has to wait for a key to quit.
+func (c Catcher) crashView() string {
+	if !c.state.drawing {
+		return ""
+	}
+	return "gg crashed. Press any key to quit."
+}
+
+// crashed writes the report of the panic p, recovered along with stack, and
+// quits.
+func (c Catcher) crashed(p any, stack []byte) (tea.Model, tea.Cmd) {
+	r := New(p, stack)
+	if d, ok := c.model.(Describer); ok {
+		d.Describe(&r)
+	}
+	c.state.err = Save(r, p)
+	return c, tea.Quit
+}
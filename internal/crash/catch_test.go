@@ -0,0 +1,139 @@
+package crash
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func boom() tea.Msg {
+	panic("boom")
+}
+
+func TestGuard(t *testing.T) {
+	if Guard(nil) != nil {
+		t.Error("guarding no command gave a command")
+	}
+
+	// A panic deep in nested batches still comes back as a message.
+	cmd := Guard(tea.Batch(func() tea.Msg { return nil }, tea.Batch(boom, boom)))
+	outer, ok := cmd().(tea.BatchMsg)
+	if !ok || len(outer) != 2 {
+		t.Fatalf("the outer batch ran as %T", cmd())
+	}
+	inner, ok := outer[1]().(tea.BatchMsg)
+	if !ok || len(inner) != 2 {
+		t.Fatalf("the inner batch ran as %T", outer[1]())
+	}
+	for _, c := range inner {
+		if msg, ok := c().(PanicMsg); !ok || msg.Value != "boom" || len(msg.Stack) == 0 {
+			t.Errorf("a panicking command sent %+v", msg)
+		}
+	}
+}
+
+// bomb is a model that panics when it gets the key of its name, in Update,
+// View or a command.
+type bomb struct {
+	drawing bool
+}
+
+func (b bomb) Init() tea.Cmd { return nil }
+
+func (b bomb) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(tea.KeyMsg).String() {
+	case "u":
+		panic("in update")
+	case "c":
+		return b, func() tea.Msg { panic("in a command") }
+	case "v":
+		b.drawing = true
+	}
+	return b, nil
+}
+
+func (b bomb) View() string {
+	if b.drawing {
+		panic("in view")
+	}
+	return "fine"
+}
+
+func (b bomb) Describe(r *Report) {
+	r.Game = "bomb"
+	r.Model = "ticking"
+}
+
+func key(k string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+// checkCrash fails the test unless c caught the panic p and wrote a report
+// with what the model described.
+func checkCrash(t *testing.T, c Catcher, p string) {
+	t.Helper()
+
+	var err *Error
+	if !errors.As(c.Err(), &err) || err.Panic != p || err.Err != nil {
+		t.Fatalf("got the error %v, want the panic %q", c.Err(), p)
+	}
+	data, _ := os.ReadFile(err.Report)
+	if report := string(data); !strings.Contains(report, "game:    bomb") || !strings.Contains(report, "ticking") {
+		t.Errorf("the report doesn't describe the model:\n%s", report)
+	}
+}
+
+func TestCatcher(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	t.Run("update", func(t *testing.T) {
+		c := Catch(bomb{})
+		m, cmd := c.Update(key("u"))
+		if !isQuit(cmd) {
+			t.Error("a panic in Update didn't quit")
+		}
+		checkCrash(t, m.(Catcher), "in update")
+	})
+
+	t.Run("command", func(t *testing.T) {
+		c := Catch(bomb{})
+		m, cmd := c.Update(key("c"))
+		msg := cmd()
+		if _, ok := msg.(PanicMsg); !ok {
+			t.Fatalf("the panicking command sent %T", msg)
+		}
+		if _, cmd = m.Update(msg); !isQuit(cmd) {
+			t.Error("a panic in a command didn't quit")
+		}
+		checkCrash(t, c, "in a command")
+	})
+
+	t.Run("view", func(t *testing.T) {
+		c := Catch(bomb{})
+		if c.View() != "fine" || c.Err() != nil {
+			t.Fatal("a model that didn't panic crashed")
+		}
+
+		m, _ := c.Update(key("v"))
+		if view := m.View(); view != "gg crashed. Press any key to quit." {
+			t.Errorf("a panic in View drew %q", view)
+		}
+		checkCrash(t, c, "in view")
+
+		// The program quits at the next message, whatever it is.
+		if _, cmd := m.Update(key("x")); !isQuit(cmd) {
+			t.Error("the message after a panic in View didn't quit")
+		}
+	})
+}
@@ -0,0 +1,167 @@
+// Package crash writes a report when gg panics, so the bug can be fixed
+// without the player having to explain how they got there.
+//
+// Reports are plain text files in the shared data directory, meant to be
+// read by people and attached to bug reports.
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/inspect"
+	"github.com/Kaamkiya/gg/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Report is what is known about a crash.
+type Report struct {
+	Time  time.Time
+	Panic string
+	Stack string
+
+	// The game being played, if any, and how it was started.
+	Game  string
+	Seed  uint64
+	Args  []string
+	Daily string
+
+	Messages []string // The last messages the game was sent, oldest first.
+	Model    string   // The state of the game's model.
+}
+
+// New returns the report of the panic p, recovered along with stack.
+func New(p any, stack []byte) Report {
+	return Report{Time: time.Now(), Panic: fmt.Sprint(p), Stack: string(stack)}
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "gg crash report\n\n")
+	fmt.Fprintf(&b, "time:    %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(&b, "version: %s\n", version())
+	fmt.Fprintf(&b, "go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "panic:   %s\n", r.Panic)
+
+	if r.Game != "" {
+		fmt.Fprintf(&b, "game:    %s\n", r.Game)
+		fmt.Fprintf(&b, "seed:    %d\n", r.Seed)
+		if len(r.Args) > 0 {
+			fmt.Fprintf(&b, "args:    %s\n", strings.Join(r.Args, " "))
+		}
+		if r.Daily != "" {
+			fmt.Fprintf(&b, "daily:   %s\n", r.Daily)
+		}
+
+		fmt.Fprintf(&b, "\nlast %d messages, oldest first:\n", len(r.Messages))
+		for _, m := range r.Messages {
+			fmt.Fprintf(&b, "  %s\n", m)
+		}
+
+		fmt.Fprintf(&b, "\nmodel:\n%s\n", r.Model)
+	}
+
+	fmt.Fprintf(&b, "\nstack:\n%s", r.Stack)
+	return b.String()
+}
+
+// version returns the version of gg, as go install stamps it.
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	return info.Main.Version
+}
+
+// Write writes r to a new file in the crashes directory, and returns its
+// path.
+func Write(r Report) (string, error) {
+	dir, err := storage.SharedDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "crashes")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := r.Game
+	if name == "" {
+		name = "gg"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.txt", name, r.Time.Format("20060102-150405")))
+
+	return path, os.WriteFile(path, []byte(r.String()), 0o644)
+}
+
+// Dump describes the state of model: its save, for games that can be saved,
+// and its fields.
+func Dump(model tea.Model) string {
+	var b strings.Builder
+
+	if s, ok := model.(game.Saver); ok {
+		version, state := s.Save()
+		if data, err := json.Marshal(state); err == nil {
+			fmt.Fprintf(&b, "save (version %d): %s\n", version, data)
+		}
+	}
+
+	b.WriteString(inspect.Format(model))
+	return b.String()
+}
+
+// History keeps the last messages sent to a game. It is safe to use from a
+// single goroutine, like a bubbletea program's Update.
+type History struct {
+	start    time.Time
+	messages []string
+}
+
+// historySize is how many messages a History keeps.
+const historySize = 50
+
+// NewHistory returns an empty history, starting now.
+func NewHistory() *History {
+	return &History{start: time.Now()}
+}
+
+// Add adds msg to the history, forgetting the oldest message if it is full.
+func (h *History) Add(msg tea.Msg) {
+	if h == nil {
+		return
+	}
+
+	s := fmt.Sprintf("%T %+v", msg, msg)
+	if str, ok := msg.(fmt.Stringer); ok {
+		s = fmt.Sprintf("%T %s", msg, str)
+	}
+
+	if len(s) > 200 {
+		s = s[:200] + "..."
+	}
+
+	if len(h.messages) == historySize {
+		h.messages = h.messages[1:]
+	}
+	h.messages = append(h.messages, fmt.Sprintf("%8.3fs  %s", time.Since(h.start).Seconds(), s))
+}
+
+// Messages returns the messages in the history, oldest first, with how long
+// after the start of the history each one came.
+func (h *History) Messages() []string {
+	if h == nil {
+		return nil
+	}
+	return h.messages
+}
@@ -0,0 +1,75 @@
+package crash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHistory(t *testing.T) {
+	var nilHistory *History
+	nilHistory.Add(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := nilHistory.Messages(); got != nil {
+		t.Errorf("a nil history has messages: %v", got)
+	}
+
+	h := NewHistory()
+	for i := range historySize + 10 {
+		h.Add(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{rune('a' + i%26)}})
+	}
+	h.Add(strings.Repeat("x", 1000))
+
+	msgs := h.Messages()
+	if len(msgs) != historySize {
+		t.Fatalf("the history kept %d messages, want %d", len(msgs), historySize)
+	}
+	if last := msgs[len(msgs)-1]; len(last) > 250 || !strings.HasSuffix(last, "...") {
+		t.Errorf("a long message wasn't shortened: %q", last)
+	}
+}
+
+type model struct {
+	theme theme.Theme
+	score int
+}
+
+func (m model) Init() tea.Cmd                       { return nil }
+func (m model) Update(tea.Msg) (tea.Model, tea.Cmd) { return m, nil }
+func (m model) View() string                        { return "" }
+
+func TestWrite(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	r := New("index out of range", []byte("goroutine 1 [running]:"))
+	r.Game = "snake"
+	r.Seed = 42
+	r.Messages = []string{"0.000s  tea.KeyMsg up"}
+	r.Model = Dump(model{theme: theme.Current(), score: 7})
+
+	path, err := Write(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir := filepath.Base(filepath.Dir(path)); dir != "crashes" {
+		t.Errorf("the report was written to %s, want a crashes directory", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, want := range []string{"index out of range", "game:    snake", "seed:    42", "tea.KeyMsg up", "score: 7", "goroutine 1"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("the report doesn't contain %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "theme:") {
+		t.Errorf("the theme was dumped:\n%s", report)
+	}
+}
@@ -0,0 +1,160 @@
+// Package daily derives the daily challenges from the calendar date and
+// keeps track of which ones were completed.
+//
+// Everyone playing on the same date gets the same puzzles, without needing a
+// network connection, because the seed only depends on the date and game.
+package daily
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+const dateFormat = "2006-01-02"
+
+// Date returns the day t falls on, in the format used by the other
+// functions in this package.
+func Date(t time.Time) string {
+	return t.Format(dateFormat)
+}
+
+// Today returns the current date.
+func Today() string {
+	return Date(time.Now())
+}
+
+// Seed returns the seed of the challenge for game on date.
+func Seed(date, game string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(date + "/" + game))
+	return h.Sum64()
+}
+
+// Entry is a completed challenge.
+type Entry struct {
+	Won      bool          `json:"won"`
+	Time     time.Duration `json:"time"`
+	Moves    int           `json:"moves"`
+	Score    int           `json:"score"`
+	Finished time.Time     `json:"finished"`
+}
+
+// Record is the history of a game's challenges.
+type Record struct {
+	Days       map[string]Entry `json:"days"`
+	Streak     int              `json:"streak"` // Days in a row won, up to Last.
+	BestStreak int              `json:"best_streak"`
+	Last       string           `json:"last"` // The last date a challenge was played.
+}
+
+// CurrentStreak returns the streak as of today: it is broken once a whole
+// day passes without winning the challenge.
+func (r Record) CurrentStreak(today string) int {
+	if r.Last == today || r.Last == previous(today) {
+		return r.Streak
+	}
+	return 0
+}
+
+func previous(date string) string {
+	t, err := time.Parse(dateFormat, date)
+	if err != nil {
+		return ""
+	}
+	return Date(t.AddDate(0, 0, -1))
+}
+
+func path() (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "daily.json"), nil
+}
+
+func load(p string) (map[string]Record, error) {
+	records := map[string]Record{}
+
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Load returns the record of game.
+func Load(game string) (Record, error) {
+	p, err := path()
+	if err != nil {
+		return Record{}, err
+	}
+
+	records, err := load(p)
+	return records[game], err
+}
+
+// Complete records that the challenge for game on date was played to the end.
+// Only the first try of a day counts, so a loss ends the day's challenge and
+// playing it again changes nothing. Only a win extends the streak; a loss
+// breaks it.
+func Complete(game, date string, e Entry) (Record, error) {
+	p, err := path()
+	if err != nil {
+		return Record{}, err
+	}
+
+	unlock, err := storage.Lock(p)
+	if err != nil {
+		return Record{}, err
+	}
+	defer unlock()
+
+	records, err := load(p)
+	if err != nil {
+		return Record{}, err
+	}
+
+	r := records[game]
+	if _, done := r.Days[date]; done {
+		return r, nil
+	}
+
+	if r.Days == nil {
+		r.Days = map[string]Entry{}
+	}
+	r.Days[date] = e
+
+	if !e.Won {
+		r.Streak = 0
+	} else if r.Last == previous(date) {
+		r.Streak++
+	} else {
+		r.Streak = 1
+	}
+	r.Last = date
+	r.BestStreak = max(r.BestStreak, r.Streak)
+	records[game] = r
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return Record{}, err
+	}
+
+	return r, storage.WriteFile(p, data)
+}
@@ -0,0 +1,66 @@
+package daily
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeed(t *testing.T) {
+	if Seed("2025-01-02", "maze") != Seed("2025-01-02", "maze") {
+		t.Error("expected the same seed for the same date and game")
+	}
+	if Seed("2025-01-02", "maze") == Seed("2025-01-03", "maze") {
+		t.Error("expected different seeds on different dates")
+	}
+	if Seed("2025-01-02", "maze") == Seed("2025-01-02", "sudoku") {
+		t.Error("expected different seeds for different games")
+	}
+}
+
+func TestStreak(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	days := []struct {
+		date   string
+		won    bool
+		streak int
+	}{
+		{"2025-02-27", true, 1},
+		{"2025-02-28", true, 2},
+		{"2025-02-28", true, 2}, // Playing the same day again doesn't count.
+		{"2025-03-01", true, 3},
+		{"2025-03-03", true, 1}, // A day was skipped.
+		{"2025-03-04", true, 2},
+		{"2025-03-05", false, 0}, // A loss breaks the streak.
+		{"2025-03-05", true, 0},  // And ends the day.
+		{"2025-03-06", true, 1},
+		{"2025-03-07", true, 2},
+	}
+
+	for _, d := range days {
+		r, err := Complete("maze", d.date, Entry{Won: d.won, Time: time.Minute, Moves: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Streak != d.streak {
+			t.Errorf("%s: expected streak %d, got %d", d.date, d.streak, r.Streak)
+		}
+	}
+
+	r, err := Load("maze")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.BestStreak != 3 {
+		t.Errorf("expected best streak 3, got %d", r.BestStreak)
+	}
+	if e := r.Days["2025-03-05"]; e.Won {
+		t.Error("expected the loss to be the day's entry")
+	}
+	if s := r.CurrentStreak("2025-03-08"); s != 2 {
+		t.Errorf("expected the streak to last until the next day, got %d", s)
+	}
+	if s := r.CurrentStreak("2025-03-09"); s != 0 {
+		t.Errorf("expected the streak to be broken, got %d", s)
+	}
+}
@@ -0,0 +1,24 @@
+package game
+
+import "math/rand/v2"
+
+// Bot is a turn-based game played by a program rather than through the
+// terminal, one move at a time. Moves are strings, e.g. "5" for the fifth
+// cell in tictactoe, so every game can be played over the same protocol.
+type Bot interface {
+	// State returns the position, in a form that encodes to JSON.
+	State() any
+
+	// Moves returns the moves that can be played next.
+	Moves() []string
+
+	// Play plays move, which is one of Moves. Games against gg's AI play
+	// its answer too.
+	Play(move string)
+
+	// Result returns how the game ended, and false if it isn't over.
+	Result() (Result, bool)
+}
+
+// BotConstructor builds a bot for a game, taking all randomness from rnd.
+type BotConstructor func(rnd *rand.Rand) Bot
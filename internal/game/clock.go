@@ -0,0 +1,118 @@
+package game
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TickMsg is sent by a Clock.
+type TickMsg struct {
+	Name string // The name of the clock, which replays store. See Descriptor.Ticks.
+}
+
+// Clock sends a TickMsg every Interval, for games where things happen
+// without the player doing anything, like the snake moving.
+//
+// A clock only sends the next tick when asked to: the model returns Init
+// from its Init method, and Next from Update once it has handled a tick.
+// Interval can be changed at any time, e.g. to speed the game up, and takes
+// effect from the next tick.
+type Clock struct {
+	Name     string
+	Interval time.Duration
+	state    *clockState // Shared between the copies bubbletea makes.
+}
+
+type clockState struct {
+	mu      sync.Mutex
+	running bool
+	gen     int  // Bumped on every stop and start, so ticks already on their way are dropped.
+	held    bool // Set by Hold.
+}
+
+// NewClock returns a running clock that sends TickMsg{Name: name}.
+func NewClock(name string, interval time.Duration) Clock {
+	return Clock{
+		Name:     name,
+		Interval: interval,
+		state:    &clockState{running: true},
+	}
+}
+
+// Init returns a command that sends the first tick.
+func (c Clock) Init() tea.Cmd {
+	return c.Next()
+}
+
+// Next returns a command that sends the next tick, or nil if c is stopped.
+func (c Clock) Next() tea.Cmd {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if !c.state.running {
+		return nil
+	}
+	return c.wait(c.state.gen)
+}
+
+// Stop stops c. A tick that is already on its way is never sent.
+func (c Clock) Stop() {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if c.state.running {
+		c.state.running = false
+		c.state.gen++
+	}
+}
+
+// Start starts c again after Stop, and returns a command that sends the
+// next tick. It returns nil if c is already running.
+func (c Clock) Start() tea.Cmd {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if c.state.running {
+		return nil
+	}
+	c.state.running = true
+	c.state.gen++
+	return c.wait(c.state.gen)
+}
+
+// Hold stops c from ever sending ticks by itself, so tests can step a game
+// by sending TickMsgs themselves.
+func (c Clock) Hold() {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	c.state.held = true
+	c.state.gen++
+}
+
+// Clocked is implemented by models with clocks, so tests can hold them.
+type Clocked interface {
+	Clocks() []Clock
+}
+
+// wait returns a command that sends a tick after Interval, unless c was
+// stopped or started again in the meantime.
+func (c Clock) wait(gen int) tea.Cmd {
+	if c.state.held {
+		return nil
+	}
+
+	name, state := c.Name, c.state
+
+	return tea.Tick(c.Interval, func(time.Time) tea.Msg {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		if !state.running || state.gen != gen {
+			return nil
+		}
+		return TickMsg{Name: name}
+	})
+}
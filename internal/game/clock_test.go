@@ -0,0 +1,51 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	c := NewClock("move", time.Millisecond)
+
+	if msg := c.Init()(); msg != (TickMsg{Name: "move"}) {
+		t.Fatalf("expected a tick, got %#v", msg)
+	}
+
+	// A tick that is on its way when the clock stops is never sent, even
+	// if the clock starts again before it is due.
+	pending := c.Next()
+	c.Stop()
+	if c.Next() != nil {
+		t.Error("expected a stopped clock not to tick")
+	}
+	next := c.Start()
+	if msg := pending(); msg != nil {
+		t.Errorf("expected the tick from before the stop to be dropped, got %#v", msg)
+	}
+	if msg := next(); msg != (TickMsg{Name: "move"}) {
+		t.Errorf("expected a tick after starting again, got %#v", msg)
+	}
+
+	if c.Start() != nil {
+		t.Error("expected starting a running clock to do nothing")
+	}
+}
+
+func TestClock_Hold(t *testing.T) {
+	held, other := NewClock("move", time.Millisecond), NewClock("move", time.Millisecond)
+
+	pending := held.Init()
+	held.Hold()
+	if held.Next() != nil || held.Start() != nil {
+		t.Error("expected a held clock not to tick")
+	}
+	if msg := pending(); msg != nil {
+		t.Errorf("expected the tick from before the hold to be dropped, got %#v", msg)
+	}
+
+	// Holding one clock leaves the others alone.
+	if msg := other.Init()(); msg != (TickMsg{Name: "move"}) {
+		t.Errorf("expected a clock that isn't held to tick, got %#v", msg)
+	}
+}
@@ -0,0 +1,47 @@
+package game
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Event is something that happened in a game that the rest of gg may want
+// to know about, like achievements: snake eating, or a maze being solved.
+// Each game names its own events.
+type Event struct {
+	Game  string // The ID of the game, filled in by the host.
+	Name  string // What happened, e.g. "ate".
+	Value int    // How much or how many, e.g. the length of the snake.
+}
+
+// EventMsg carries an event from a game to the host, which passes it on to
+// whoever subscribed to the game's events. Games never receive it.
+type EventMsg struct {
+	Event Event
+}
+
+// Publish returns a command that publishes the event name, with value.
+// Games return it alongside their other commands.
+func Publish(name string, value int) tea.Cmd {
+	return func() tea.Msg {
+		return EventMsg{Event: Event{Name: name, Value: value}}
+	}
+}
+
+// Bus passes the events games publish on to the functions subscribed to it.
+// The zero value has no subscribers.
+type Bus struct {
+	subscribers []func(Event) tea.Cmd
+}
+
+// Subscribe calls f with every event published from now on. The command f
+// returns, if any, is run by the host.
+func (b *Bus) Subscribe(f func(Event) tea.Cmd) {
+	b.subscribers = append(b.subscribers, f)
+}
+
+// Publish passes e to every subscriber, and returns their commands.
+func (b *Bus) Publish(e Event) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, f := range b.subscribers {
+		cmds = append(cmds, f(e))
+	}
+	return tea.Batch(cmds...)
+}
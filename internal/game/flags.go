@@ -0,0 +1,41 @@
+package game
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ChoiceVar defines a string flag that only accepts one of choices. The
+// value p points to is used as the default.
+func ChoiceVar(fs *flag.FlagSet, p *string, name, usage string, choices ...string) {
+	usage = fmt.Sprintf("%s (%s) (default %q)", usage, strings.Join(choices, ", "), *p)
+
+	fs.Func(name, usage, func(s string) error {
+		if !slices.Contains(choices, s) {
+			return fmt.Errorf("must be one of %s", strings.Join(choices, ", "))
+		}
+		*p = s
+		return nil
+	})
+}
+
+// IntRangeVar defines an int flag that only accepts values between min and
+// max, inclusive. The value p points to is used as the default.
+func IntRangeVar(fs *flag.FlagSet, p *int, name, usage string, min, max int) {
+	usage = fmt.Sprintf("%s, %d to %d (default %d)", usage, min, max, *p)
+
+	fs.Func(name, usage, func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		if n < min || n > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		*p = n
+		return nil
+	})
+}
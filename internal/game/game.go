@@ -0,0 +1,111 @@
+// Package game keeps track of every game gg knows how to run.
+//
+// Each game package registers a Descriptor from its init function, and the
+// menu and the command line are both built from the registered descriptors.
+package game
+
+import (
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type Category string
+
+const (
+	Puzzle Category = "puzzle"
+	Arcade Category = "arcade"
+	Board  Category = "board"
+	Word   Category = "word"
+)
+
+// Descriptor describes a single game.
+type Descriptor struct {
+	ID          string // The key used to select the game, e.g. "snake".
+	Name        string // The name shown to the player.
+	Players     int
+	Category    Category
+	Description string
+
+	// Score names what Result.Score counts, like "length" for snake, for
+	// statistics that compare scores. It is empty for games whose scores
+	// aren't worth comparing.
+	Score string
+
+	// New builds a fresh model for the game with its default options.
+	New Constructor
+
+	// Flags is optional. It registers the game's options on fs and returns
+	// a constructor that builds the model from the parsed values.
+	Flags func(fs *flag.FlagSet) Constructor
+
+	// Resume is optional. It rebuilds a model from a save made by the
+	// model's Save method. See Saver.
+	Resume func(s Save, rnd *rand.Rand) (tea.Model, error)
+
+	// Daily marks games offered as a daily challenge. Challenges are played
+	// with New, from a seed derived from the date, so everyone gets the same
+	// puzzle on the same day.
+	Daily bool
+
+	// Ticks lists the names of the clocks the game's models run, so
+	// replays can store their ticks. See Clock.
+	Ticks []string
+
+	// Bot is optional, for turn-based games that programs can play with
+	// gg serve-bot. Like Flags, it registers the bot's options on fs and
+	// returns a constructor that builds it from the parsed values.
+	Bot func(fs *flag.FlagSet) BotConstructor
+
+	// Versus is optional, for two-player games that can be played over the
+	// network with gg host and gg join. It builds the model of the player
+	// in seat. See Versus.
+	Versus func(seat Seat) Versus
+}
+
+// Label returns the name of the game, followed by the amount of players if
+// there is more than one.
+func (d Descriptor) Label() string {
+	if d.Players > 1 {
+		return fmt.Sprintf("%s (%d player)", d.Name, d.Players)
+	}
+	return d.Name
+}
+
+var games = map[string]Descriptor{}
+
+// Register adds a game to the registry. It panics if the descriptor is
+// incomplete or the ID is already taken, since both are programming errors.
+func Register(d Descriptor) {
+	if d.ID == "" || d.New == nil {
+		panic("game: descriptor needs an ID and a constructor")
+	}
+	if _, ok := games[d.ID]; ok {
+		panic("game: " + d.ID + " registered twice")
+	}
+	games[d.ID] = d
+}
+
+// Lookup returns the game registered under id.
+func Lookup(id string) (Descriptor, bool) {
+	d, ok := games[id]
+	return d, ok
+}
+
+// All returns every registered game, sorted by name.
+func All() []Descriptor {
+	all := make([]Descriptor, 0, len(games))
+	for _, d := range games {
+		all = append(all, d)
+	}
+
+	slices.SortFunc(all, func(a, b Descriptor) int {
+		return strings.Compare(a.Label(), b.Label())
+	})
+
+	return all
+}
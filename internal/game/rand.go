@@ -0,0 +1,22 @@
+package game
+
+import (
+	"math/rand/v2"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Constructor builds a fresh model for a game. Every random choice the game
+// makes must come from rnd, so that a game can be played again exactly by
+// starting it with the same seed.
+type Constructor func(rnd *rand.Rand) tea.Model
+
+// NewRand returns the random source for a game started with seed.
+func NewRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// RandomSeed returns a seed for a game that wasn't given one.
+func RandomSeed() uint64 {
+	return rand.Uint64()
+}
@@ -0,0 +1,56 @@
+package game
+
+import tea "github.com/charmbracelet/bubbletea"
+
+type Outcome int
+
+const (
+	Quit Outcome = iota // The player left before the game was over.
+	Won
+	Lost
+	Draw
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Won:
+		return "won"
+	case Lost:
+		return "lost"
+	case Draw:
+		return "draw"
+	default:
+		return "quit"
+	}
+}
+
+// Result describes how a game ended.
+type Result struct {
+	Outcome Outcome
+	Score   int
+	Moves   int    // How many moves the player made, for games that count them.
+	Summary string // A short line shown on the results screen.
+
+	// Matches is how each match went, for games of several matches like
+	// tictactoe against the AI.
+	Matches []Outcome
+}
+
+// OverMsg is sent when a game has ended.
+type OverMsg struct {
+	Result Result
+}
+
+// Over returns a command that ends the game. Games return it from Update
+// instead of tea.Quit, so the player is taken to the results screen rather
+// than back to the shell.
+func Over(r Result) tea.Cmd {
+	return func() tea.Msg {
+		return OverMsg{Result: r}
+	}
+}
+
+// SuspendMsg asks a game to pause, e.g. because the terminal became too
+// small to show it. Games with an overlay show its pause screen, so the
+// player chooses when to carry on.
+type SuspendMsg struct{}
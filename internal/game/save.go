@@ -0,0 +1,32 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Saver is implemented by models that can be saved when the player leaves
+// part way through a game, to be picked up again with Descriptor.Resume.
+type Saver interface {
+	// Save returns the state of the game, which must marshal to JSON, and
+	// the version of its format. The version should be bumped whenever the
+	// format changes, so Resume can still read older saves.
+	Save() (version int, state any)
+}
+
+// Save is a saved game, as passed to Descriptor.Resume.
+type Save struct {
+	Version int
+	State   json.RawMessage
+}
+
+// Decode unmarshals the saved state into v. It fails if the save was written
+// in a newer format than latest, which happens when an older gg reads a save
+// made by a newer one.
+func (s Save) Decode(latest int, v any) error {
+	if s.Version < 1 || s.Version > latest {
+		return fmt.Errorf("save format version %d is not supported", s.Version)
+	}
+
+	return json.Unmarshal(s.State, v)
+}
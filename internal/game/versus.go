@@ -0,0 +1,50 @@
+package game
+
+import (
+	"encoding/json"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Seat is the player a model plays for in a game played over the network.
+type Seat int
+
+const (
+	Host  Seat = iota + 1 // The player who started the game, who goes first.
+	Guest                 // The player who joined.
+)
+
+// MoveMsg is a move made by the player in Seat, in a game played over the
+// network.
+type MoveMsg struct {
+	Seat Seat
+	Move string
+}
+
+// Move returns a command that sends a MoveMsg. Models of network games
+// return it for their own player's moves instead of playing them, so that
+// the host can check them first.
+func Move(seat Seat, move string) tea.Cmd {
+	return func() tea.Msg {
+		return MoveMsg{Seat: seat, Move: move}
+	}
+}
+
+// Versus is a model of a two-player game played over the network, each
+// player running gg on their own machine.
+//
+// The host's model is the one that counts: it plays the moves of both
+// players, after checking that they are allowed, and runs the game's
+// clocks. Its state is sent to the guest after every change, so the guest's
+// model only draws the game and sends its player's moves.
+type Versus interface {
+	tea.Model
+
+	// State returns the state of the game, to be sent to the guest as JSON.
+	State() any
+
+	// Sync replaces the state of the guest's model with state, as encoded
+	// from the host's State. The command is nil, unless the game is over
+	// and it ends the game.
+	Sync(state json.RawMessage) (Versus, tea.Cmd, error)
+}
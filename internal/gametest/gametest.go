@@ -0,0 +1,211 @@
+// Package gametest drives game models in tests, without a terminal.
+//
+// A test starts a game from a fixed seed, sends it a script of keys and
+// ticks, then checks the model's state, how the game ended, or its view
+// against a golden file:
+//
+//	g := gametest.Start(t, initialModel, 1)
+//	g.Keys("up", "up").Tick("move", 3)
+//	g.Golden("moved_up")
+//
+// The clocks of games that implement game.Clocked are held, so ticks only
+// happen when the test sends them. Run the tests with -update to rewrite the golden files.
+package gametest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// Game is a game being driven by a test.
+type Game struct {
+	t      testing.TB
+	model  tea.Model
+	result *game.Result // Set once the game is over.
+	events []game.Event
+}
+
+// Start builds a game with newModel from seed and runs its Init command.
+// Scores, saves and settings are kept in temporary directories, so the
+// player's own files are neither read nor written. Views are drawn without
+// colours, so golden files are the same in every terminal.
+func Start(t testing.TB, newModel game.Constructor, seed uint64) *Game {
+	t.Helper()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	g := &Game{t: t}
+	g.SetModel(newModel(game.NewRand(seed)))
+	g.run(g.model.Init())
+	return g
+}
+
+// Model returns the game's model, which tests type assert to look at its
+// state.
+func (g *Game) Model() tea.Model {
+	return g.model
+}
+
+// SetModel replaces the game's model, e.g. with one whose state the test
+// changed to set up a position.
+func (g *Game) SetModel(m tea.Model) {
+	g.model = m
+	if c, ok := m.(game.Clocked); ok {
+		for _, clock := range c.Clocks() {
+			clock.Hold()
+		}
+	}
+}
+
+// Send sends msgs to the game one after the other, along with whatever
+// their commands send. It fails the test if the game is already over.
+func (g *Game) Send(msgs ...tea.Msg) *Game {
+	g.t.Helper()
+
+	for _, msg := range msgs {
+		if g.result != nil {
+			g.t.Fatalf("sent %T to a game that is over", msg)
+		}
+		g.update(msg)
+	}
+	return g
+}
+
+// Keys sends a key press for each of keys, named like keys.json names them,
+// e.g. "up", "enter", "esc", " " or "q".
+func (g *Game) Keys(keys ...string) *Game {
+	g.t.Helper()
+
+	for _, k := range keys {
+		g.Send(Key(k))
+	}
+	return g
+}
+
+// Tick sends n ticks of the clock called name.
+func (g *Game) Tick(name string, n int) *Game {
+	g.t.Helper()
+
+	for range n {
+		g.Send(game.TickMsg{Name: name})
+	}
+	return g
+}
+
+// Resize tells the game the terminal is width by height.
+func (g *Game) Resize(width, height int) *Game {
+	g.t.Helper()
+
+	return g.Send(tea.WindowSizeMsg{Width: width, Height: height})
+}
+
+// Result returns how the game ended, and false if it isn't over.
+func (g *Game) Result() (game.Result, bool) {
+	if g.result == nil {
+		return game.Result{}, false
+	}
+	return *g.result, true
+}
+
+// Events returns the events the game published, in order.
+func (g *Game) Events() []game.Event {
+	return g.events
+}
+
+// View returns the game's view.
+func (g *Game) View() string {
+	return g.model.View()
+}
+
+// Golden compares the game's view to testdata/name.golden, or writes the
+// file when the tests are run with -update.
+func (g *Game) Golden(name string) {
+	g.t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	got := g.View()
+
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			g.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			g.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		g.t.Fatalf("%v; run the tests with -update to create it", err)
+	}
+	if got != string(want) {
+		g.t.Errorf("view doesn't match %s:\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// update sends msg to the model and runs the command it returns.
+func (g *Game) update(msg tea.Msg) {
+	var cmd tea.Cmd
+	g.model, cmd = g.model.Update(msg)
+	g.run(cmd)
+}
+
+// run runs cmd and sends what it returns back to the model, the way a
+// program would. Commands run one at a time and in order, so a script always
+// has the same effect.
+func (g *Game) run(cmd tea.Cmd) {
+	if cmd == nil || g.result != nil {
+		return
+	}
+
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			g.run(cmd)
+		}
+	case game.OverMsg:
+		g.result = &msg.Result
+	case game.EventMsg:
+		g.events = append(g.events, msg.Event)
+	default:
+		g.update(msg)
+	}
+}
+
+// keyTypes are the keys with names of their own.
+var keyTypes = map[string]tea.KeyType{
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"backspace": tea.KeyBackspace,
+	"ctrl+c":    tea.KeyCtrlC,
+}
+
+// Key returns the message sent when the key k is pressed.
+func Key(k string) tea.KeyMsg {
+	if t, ok := keyTypes[k]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	if k == " " {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(k)}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
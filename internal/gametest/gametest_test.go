@@ -0,0 +1,50 @@
+package gametest
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKey(t *testing.T) {
+	for _, k := range []string{"up", "down", "left", "right", "enter", "esc", "tab", "backspace", "ctrl+c", " ", "q", "?"} {
+		if got := Key(k).String(); got != k {
+			t.Errorf("Key(%q) is pressed as %q", k, got)
+		}
+	}
+}
+
+// ticker counts the ticks of a clock too slow to ever tick in a test.
+type ticker struct {
+	clock game.Clock
+	ticks int
+}
+
+func (m ticker) Init() tea.Cmd { return m.clock.Init() }
+
+func (m ticker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(game.TickMsg); ok {
+		m.ticks++
+		return m, m.clock.Next()
+	}
+	return m, nil
+}
+
+func (m ticker) View() string { return "" }
+
+func (m ticker) Clocks() []game.Clock { return []game.Clock{m.clock} }
+
+func TestStart_HoldsClocks(t *testing.T) {
+	g := Start(t, func(*rand.Rand) tea.Model {
+		return ticker{clock: game.NewClock("tick", time.Hour)}
+	}, 1)
+
+	g.Tick("tick", 2)
+	if n := g.Model().(ticker).ticks; n != 2 {
+		t.Errorf("the game got %d ticks, want the 2 the test sent", n)
+	}
+}
@@ -0,0 +1,670 @@
+// Package host runs the menu and the games inside a single program, so
+// finishing a game leads back to the menu instead of the shell.
+package host
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/achievements"
+	"github.com/Kaamkiya/gg/internal/crash"
+	"github.com/Kaamkiya/gg/internal/daily"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/inspect"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/menu"
+	"github.com/Kaamkiya/gg/internal/replay"
+	"github.com/Kaamkiya/gg/internal/saves"
+	"github.com/Kaamkiya/gg/internal/stats"
+	"github.com/Kaamkiya/gg/internal/storage"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// events passes on the events games publish to whatever keeps track of them.
+var events game.Bus
+
+func init() {
+	events.Subscribe(achievements.Observe)
+}
+
+type state int
+
+const (
+	inMenu state = iota
+	choosingProfile
+	showingPage
+	playing
+	showingResults
+)
+
+// Model is the top level model. It shows the menu, the game being played or
+// the results of the last game, depending on its state.
+type Model struct {
+	state  state
+	width  int
+	height int
+	theme  theme.Theme
+
+	menu    menu.Model
+	menuErr error // Shown above the menu, e.g. when a save can't be resumed.
+
+	profiles     *huh.Form // Set while choosing a profile.
+	fixedProfile bool      // Whether the profile picker is left out of the menu.
+
+	page       viewport.Model // The statistics or achievements, scrolled by the player.
+	pageOption string         // The menu option that shows the page.
+
+	toasts []achievements.Achievement // Unlocked achievements still to be announced.
+
+	current   game.Descriptor
+	newModel  game.Constructor
+	args      []string // The command line flags newModel was built from.
+	seed      uint64   // The seed the current game's random source started from.
+	game      tea.Model
+	started   time.Time
+	earlier   time.Duration // How long the game was played for before it was resumed.
+	presses   int           // How many keys reached the game.
+	daily     string        // The date of the daily challenge being played, if any.
+	resumed   string        // The ID of the save the game was resumed from, if it was.
+	recording *replay.File
+	player    *player // Set when watching a replay rather than playing.
+	tooSmall  bool    // Whether the game doesn't fit in the terminal.
+
+	inspecting bool // Whether the inspector is shown next to the game, with --debug.
+
+	result     game.Result
+	lastView   string // The last frame of the game that just ended.
+	saved      bool   // Whether the game that just ended was saved.
+	saveErr    error
+	record     *daily.Record // Set when the game that just ended was a daily challenge.
+	dailyErr   error
+	replayPath string // Where the game that just ended was recorded.
+	replayErr  error
+	statsErr   error
+	results    *huh.Form
+
+	history *crash.History // The last messages sent to the game, for a crash report.
+}
+
+// New returns a model that starts at the menu.
+func New() Model {
+	m := Model{
+		state:   inMenu,
+		theme:   theme.Current(),
+		menuErr: checkConfig(),
+	}
+	m.menu = m.newMenu()
+	return m
+}
+
+// NewChoosingProfile returns a model that asks which profile to play in
+// before showing the menu, if there is more than the default one.
+func NewChoosingProfile() Model {
+	m := New()
+	if names, _ := storage.Profiles(); len(names) > 1 {
+		m.state = choosingProfile
+		m.profiles = newProfiles()
+	}
+	return m
+}
+
+// NewShared returns a model that starts at the menu, for a player who shares
+// the process with others, like everyone playing over SSH. The profile is
+// the same for the whole process, so they can't switch it.
+func NewShared() Model {
+	m := New()
+	m.fixedProfile = true
+	m.menu = m.newMenu()
+	return m
+}
+
+// checkConfig reports a problem with the current profile's settings. Games
+// fall back to their default keys, but the player should know why their own
+// aren't working.
+func checkConfig() error {
+	if err := theme.Check(); err != nil {
+		return fmt.Errorf("couldn't load the theme: %w", err)
+	}
+	if _, err := keys.ReadConfig(); err != nil {
+		return fmt.Errorf("couldn't read the key bindings: %w", err)
+	}
+	return nil
+}
+
+// NewPlaying returns a model that starts straight in the game d, using
+// newModel to build it from seed. args are the command line flags newModel
+// was built from, which are kept in the game's replay. The menu is shown
+// once the player leaves the game.
+func NewPlaying(d game.Descriptor, args []string, newModel game.Constructor, seed uint64) Model {
+	m := Model{theme: theme.Current()}
+	m.start(d, newModel, args, seed)
+	return m
+}
+
+// NewDaily returns a model that starts straight in today's challenge for d.
+func NewDaily(d game.Descriptor) Model {
+	m := Model{theme: theme.Current()}
+	m.startDaily(d, daily.Today())
+	return m
+}
+
+// Run runs m in a new program and blocks until the player quits. m is a
+// Model, or a model wrapping one, like the crash.Catcher that writes a crash
+// report if gg panics.
+func Run(m tea.Model) error {
+	_, err := tea.NewProgram(m).Run()
+	return err
+}
+
+// Describe adds the game being played, and how it was started, to the report
+// of a crash.
+func (m Model) Describe(r *crash.Report) {
+	if m.game == nil {
+		return
+	}
+
+	r.Game = m.current.ID
+	r.Seed = m.seed
+	r.Args = m.args
+	r.Daily = m.daily
+	r.Messages = m.history.Messages()
+	r.Model = crash.Dump(m.game)
+}
+
+func (m Model) Init() tea.Cmd {
+	switch m.state {
+	case choosingProfile:
+		return m.profiles.Init()
+	case playing:
+		return tea.Batch(m.game.Init(), m.player.wait())
+	}
+
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	name, start := m.name(), time.Now()
+	model, cmd := m.update(msg)
+	inspect.Update(name, msg, time.Since(start))
+
+	return model, cmd
+}
+
+// name names the model messages go to, for the debug log: the game being
+// played, or the screen shown.
+func (m Model) name() string {
+	switch m.state {
+	case inMenu:
+		return "menu"
+	case choosingProfile:
+		return "profiles"
+	case showingPage:
+		return "page"
+	case showingResults:
+		return "results"
+	}
+	return m.current.ID
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case game.EventMsg:
+		// Events are for the rest of gg, not the game, so they are neither
+		// sent to it nor recorded.
+		e := msg.Event
+		e.Game = m.current.ID
+		return m, events.Publish(e)
+	case achievements.UnlockedMsg:
+		m.toasts = append(m.toasts, msg.Achievement)
+		if len(m.toasts) == 1 {
+			return m, toastTimeout()
+		}
+		return m, nil
+	case toastDoneMsg:
+		if len(m.toasts) > 0 {
+			m.toasts = m.toasts[1:]
+		}
+		if len(m.toasts) > 0 {
+			return m, toastTimeout()
+		}
+		return m, nil
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "f12" && m.state == playing && inspect.Enabled() {
+		// The inspector is for whoever works on gg, so the key is neither
+		// sent to the game nor recorded.
+		m.inspecting = !m.inspecting
+		return m, nil
+	}
+
+	if m.player != nil {
+		return m.updateReplay(msg)
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			if m.state == playing {
+				saved, _ := m.persist(game.Quit)
+				m.saveReplay()
+				m.logSession(game.Result{Outcome: game.Quit}, saved)
+			}
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+
+	switch m.state {
+	case inMenu:
+		return m.updateMenu(msg)
+	case choosingProfile:
+		return m.updateProfiles(msg)
+	case showingPage:
+		return m.updatePage(msg)
+	case playing:
+		return m.updateGame(msg)
+	case showingResults:
+		return m.updateResults(msg)
+	}
+
+	return m, nil
+}
+
+func (m Model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var res menu.Result
+	m.menu, res = m.menu.Update(msg)
+
+	switch res {
+	case menu.Quit:
+		return m, tea.Quit
+	case menu.Chosen:
+		choice := m.menu.Choice()
+		m.menuErr = nil
+
+		if choice == profileOption {
+			m.state = choosingProfile
+			m.profiles = newProfiles()
+			return m, m.profiles.Init()
+		}
+		if choice == statsOption || choice == achievementsOption {
+			m.state = showingPage
+			m.pageOption = choice
+			m.page = m.newPage(choice)
+			return m, nil
+		}
+
+		if id, ok := strings.CutPrefix(choice, resumePrefix); ok {
+			cmd, err := m.resume(id)
+			if err != nil {
+				m.menuErr = fmt.Errorf("couldn't resume the save %s: %w", id, err)
+				return m, nil
+			}
+			return m, cmd
+		}
+
+		if id, ok := strings.CutPrefix(choice, dailyPrefix); ok {
+			d, ok := game.Lookup(id)
+			if !ok {
+				return m, nil
+			}
+			return m, m.startDaily(d, daily.Today())
+		}
+
+		d, ok := game.Lookup(choice)
+		if !ok {
+			return m, nil
+		}
+		return m, m.start(d, d.New, nil, game.RandomSeed())
+	}
+
+	return m, nil
+}
+
+func (m Model) updateProfiles(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form, cmd := m.profiles.Update(msg)
+	m.profiles = form.(*huh.Form)
+
+	switch m.profiles.State {
+	case huh.StateAborted:
+		return m, tea.Quit
+	case huh.StateCompleted:
+		name := m.profiles.GetString("profile")
+		if name == newProfileOption {
+			name = m.profiles.GetString("name")
+		}
+
+		m.state = inMenu
+		m.profiles = nil
+		if err := m.switchProfile(name); err != nil {
+			m.menuErr = fmt.Errorf("couldn't switch to %s: %w", name, err)
+		}
+		m.menu = m.newMenu()
+		return m, m.resize()
+	}
+
+	return m, cmd
+}
+
+// switchProfile makes name the current profile, creating it if needed, and
+// picks up its settings.
+func (m *Model) switchProfile(name string) error {
+	if err := storage.CreateProfile(name); err != nil {
+		return err
+	}
+	if err := storage.SetProfile(name); err != nil {
+		return err
+	}
+
+	m.theme = theme.Current()
+	m.menuErr = checkConfig()
+	return nil
+}
+
+func (m Model) updatePage(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "enter":
+			m.state = inMenu
+			m.menu = m.newMenu()
+			return m, m.resize()
+		}
+	case tea.WindowSizeMsg:
+		m.page = m.newPage(m.pageOption)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.page, cmd = m.page.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateGame(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(game.OverMsg); ok {
+		m.saved, m.saveErr = m.persist(msg.Result.Outcome)
+		m.result = msg.Result
+		m.record, m.dailyErr = m.completeDaily(msg.Result)
+		m.replayPath, m.replayErr = m.saveReplay()
+		m.statsErr = m.logSession(msg.Result, m.saved)
+		m.lastView = m.game.View()
+		m.game = nil
+		m.state = showingResults
+		m.results = newResults()
+		return m, m.results.Init()
+	}
+
+	if _, ok := msg.(tea.KeyMsg); ok && m.tooSmall {
+		// The player can't see the game, so nothing they type reaches it
+		// until the terminal is big enough again.
+		return m, nil
+	}
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.presses++
+	}
+
+	m.recording.Add(m.current, time.Since(m.started), msg)
+	m.history.Add(msg)
+
+	var cmd tea.Cmd
+	m.game, cmd = m.game.Update(msg)
+
+	if _, ok := msg.(tea.WindowSizeMsg); ok {
+		m.tooSmall = !m.fits(m.game.View())
+		if m.tooSmall {
+			cmd = tea.Batch(cmd, suspend)
+		}
+	}
+
+	return m, cmd
+}
+
+// toastDoneMsg takes down the oldest toast.
+type toastDoneMsg struct{}
+
+// toastTimeout returns a command that takes down the oldest toast once it
+// was shown for long enough to read.
+func toastTimeout() tea.Cmd {
+	return tea.Tick(3*time.Second, func(time.Time) tea.Msg {
+		return toastDoneMsg{}
+	})
+}
+
+// suspend pauses the game while the terminal is too small to show it.
+func suspend() tea.Msg {
+	return game.SuspendMsg{}
+}
+
+// fits reports whether view fits in the terminal. Until the size of the
+// terminal is known, everything fits.
+func (m Model) fits(view string) bool {
+	if m.width == 0 && m.height == 0 {
+		return true
+	}
+
+	w, h := lipgloss.Size(view)
+	return w <= m.width && h <= m.height
+}
+
+// center places view in the middle of the terminal.
+func (m Model) center(view string) string {
+	if m.width == 0 && m.height == 0 {
+		return view
+	}
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, view)
+}
+
+func (m Model) updateResults(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form, cmd := m.results.Update(msg)
+	m.results = form.(*huh.Form)
+
+	switch m.results.State {
+	case huh.StateAborted:
+		return m, tea.Quit
+	case huh.StateCompleted:
+		switch m.results.GetString("action") {
+		case "again":
+			if m.daily != "" {
+				return m, m.startDaily(m.current, m.daily)
+			}
+			return m, m.start(m.current, m.newModel, m.args, game.RandomSeed())
+		case "menu":
+			m.state = inMenu
+			m.menu = m.newMenu()
+			return m, m.resize()
+		default:
+			return m, tea.Quit
+		}
+	}
+
+	return m, cmd
+}
+
+// start switches to playing a fresh game of d.
+func (m *Model) start(d game.Descriptor, newModel game.Constructor, args []string, seed uint64) tea.Cmd {
+	return m.play(d, newModel, args, seed, newModel(game.NewRand(seed)))
+}
+
+// startDaily switches to playing the daily challenge for d on date.
+func (m *Model) startDaily(d game.Descriptor, date string) tea.Cmd {
+	cmd := m.start(d, d.New, nil, daily.Seed(date, d.ID))
+	m.daily = date
+	return cmd
+}
+
+// completeDaily records the result of a daily challenge that was played to
+// the end. It returns nil if the game wasn't a daily challenge or was quit.
+func (m Model) completeDaily(r game.Result) (*daily.Record, error) {
+	if m.daily == "" || r.Outcome == game.Quit {
+		return nil, nil
+	}
+
+	record, err := daily.Complete(m.current.ID, m.daily, daily.Entry{
+		Won:      r.Outcome == game.Won,
+		Time:     time.Since(m.started).Round(time.Second),
+		Moves:    r.Moves,
+		Score:    r.Score,
+		Finished: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// resume switches to playing the save with the given ID.
+func (m *Model) resume(id string) (tea.Cmd, error) {
+	f, err := saves.Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := game.Lookup(f.Game)
+	if !ok || d.Resume == nil {
+		return nil, fmt.Errorf("unknown game %q", f.Game)
+	}
+
+	seed := game.RandomSeed()
+	model, err := d.Resume(game.Save{Version: f.Version, State: f.State}, game.NewRand(seed))
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := m.play(d, d.New, nil, seed, model)
+	m.recording.Resume = &replay.Save{Version: f.Version, State: f.State}
+	m.resumed = id
+	m.earlier = f.Played
+	return cmd, nil
+}
+
+// play switches to playing model, a game of d whose random source started
+// from seed. newModel and args are used if the player chooses to play again.
+func (m *Model) play(d game.Descriptor, newModel game.Constructor, args []string, seed uint64, model tea.Model) tea.Cmd {
+	m.state = playing
+	m.current = d
+	m.newModel = newModel
+	m.args = args
+	m.seed = seed
+	m.game = model
+	m.tooSmall = false
+	m.started = time.Now()
+	m.earlier = 0
+	m.presses = 0
+	m.daily = ""
+	m.resumed = ""
+	m.recording = replay.New(d, args, seed)
+	m.history = crash.NewHistory()
+
+	return tea.Batch(m.game.Init(), m.resize())
+}
+
+// persist saves the current game if the player quit part way through it,
+// replacing the save it was resumed from or as a save of its own. Once a
+// game is over there is nothing left to resume, so the save it was resumed
+// from is removed instead; other saves of the same game are left alone.
+// Daily challenges are never saved; they can be started again from the menu
+// for the rest of the day. It reports whether the game was saved.
+func (m Model) persist(outcome game.Outcome) (bool, error) {
+	saver, ok := m.game.(game.Saver)
+	if !ok || m.daily != "" {
+		return false, nil
+	}
+
+	if outcome != game.Quit {
+		if m.resumed == "" {
+			return false, nil
+		}
+		return false, saves.Remove(m.resumed)
+	}
+
+	id := m.resumed
+	if id == "" {
+		id = saves.NewID(m.current.ID)
+	}
+	version, state := saver.Save()
+	err := saves.Write(id, m.current.ID, version, m.earlier+time.Since(m.started), state)
+	return err == nil, err
+}
+
+// logSession adds the current game, which ended with r, to the statistics.
+// A game that was saved is logged as unfinished, and the session that
+// resumes it adds the time played before.
+func (m Model) logSession(r game.Result, saved bool) error {
+	s := stats.New(m.current.ID, m.started, r)
+	s.Keys = m.presses
+	s.Daily = m.daily != ""
+	s.Saved = saved
+	s.Earlier = m.earlier
+	return stats.Log(s)
+}
+
+// saveReplay writes the recording of the current game to the game's last
+// replay file, and returns the path.
+func (m Model) saveReplay() (string, error) {
+	path, err := replay.Last(m.current.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return path, replay.Write(path, m.recording)
+}
+
+// resize repeats the last known window size, so models that were created
+// after the program started still learn it.
+func (m Model) resize() tea.Cmd {
+	if m.width == 0 && m.height == 0 {
+		return nil
+	}
+
+	size := tea.WindowSizeMsg{Width: m.width, Height: m.height}
+	return func() tea.Msg {
+		return size
+	}
+}
+
+// View draws the current screen.
+func (m Model) View() string {
+	return m.withToast(m.view())
+}
+
+func (m Model) view() string {
+	switch m.state {
+	case inMenu:
+		if m.menuErr != nil {
+			return m.theme.Error.Render("Error: "+m.menuErr.Error()) + "\n\n" + m.menu.View()
+		}
+		return m.menu.View()
+	case choosingProfile:
+		return m.profiles.View()
+	case showingPage:
+		return m.page.View() + "\n" + seedStyle.Render("↑/↓ scroll • q back to menu")
+	case playing:
+		var view string
+		switch {
+		case m.player != nil:
+			view = m.replayView()
+		case m.tooSmall:
+			return m.tooSmallView()
+		default:
+			view = m.game.View()
+		}
+
+		if m.inspecting {
+			return m.withInspector(view)
+		}
+		return m.center(view)
+	case showingResults:
+		return m.resultsView()
+	}
+
+	return ""
+}
@@ -0,0 +1,224 @@
+package host
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/crash"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/saves"
+	"github.com/Kaamkiya/gg/internal/stats"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// counter is a game that counts the times + was pressed, and can be saved.
+// Pressing ! makes it panic the next time it's drawn.
+type counter struct {
+	n     int
+	broke bool
+}
+
+func (c counter) Init() tea.Cmd { return nil }
+
+func (c counter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "+":
+			c.n++
+		case "!":
+			c.broke = true
+		}
+	}
+	return c, nil
+}
+
+func (c counter) View() string {
+	if c.broke {
+		panic("counter broke")
+	}
+	return ""
+}
+
+func (c counter) Save() (int, any) { return 1, c.n }
+
+func resumeCounter(s game.Save, _ *rand.Rand) (tea.Model, error) {
+	var c counter
+	err := s.Decode(1, &c.n)
+	return c, err
+}
+
+var counterGame = game.Descriptor{
+	ID:       "counter",
+	Name:     "counter",
+	Players:  1,
+	Category: game.Puzzle,
+	New:      func(*rand.Rand) tea.Model { return counter{} },
+	Resume:   resumeCounter,
+}
+
+func init() {
+	game.Register(counterGame)
+}
+
+// send sends msgs to m one at a time, without running the commands they
+// return.
+func send(m Model, msgs ...tea.Msg) Model {
+	for _, msg := range msgs {
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	return m
+}
+
+// press returns the message of a key press.
+func press(k string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// over ends the game with outcome.
+func over(outcome game.Outcome) game.OverMsg {
+	return game.OverMsg{Result: game.Result{Outcome: outcome}}
+}
+
+// savedCounts returns the count in each save, by the save's ID.
+func savedCounts(t *testing.T) map[string]int {
+	t.Helper()
+	files, err := saves.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{}
+	for _, f := range files {
+		var n int
+		if err := (game.Save{Version: f.Version, State: f.State}).Decode(1, &n); err != nil {
+			t.Fatal(err)
+		}
+		counts[f.ID] = n
+	}
+	return counts
+}
+
+func TestSaves(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// Quitting a game saves it.
+	m := send(NewPlaying(counterGame, nil, counterGame.New, 1), press("+"), over(game.Quit))
+	if !m.saved || m.saveErr != nil {
+		t.Fatalf("quitting didn't save the game: %v", m.saveErr)
+	}
+	files, _ := saves.List()
+	if len(files) != 1 {
+		t.Fatalf("expected one save, got %d", len(files))
+	}
+	id := files[0].ID
+
+	// Quitting another game of counter keeps the first save.
+	send(NewPlaying(counterGame, nil, counterGame.New, 2), press("+"), press("+"), over(game.Quit))
+	if counts := savedCounts(t); len(counts) != 2 || counts[id] != 1 {
+		t.Fatalf("a second game replaced the first save: %v", counts)
+	}
+
+	// Finishing a fresh game removes no save.
+	send(NewPlaying(counterGame, nil, counterGame.New, 3), over(game.Won))
+	if counts := savedCounts(t); len(counts) != 2 {
+		t.Fatalf("finishing a fresh game left the saves %v", counts)
+	}
+
+	// Quitting a resumed game replaces its own save.
+	m = New()
+	if _, err := m.resume(id); err != nil {
+		t.Fatal(err)
+	}
+	m = send(m, press("+"), over(game.Quit))
+	if counts := savedCounts(t); len(counts) != 2 || counts[id] != 2 {
+		t.Fatalf("quitting the resumed game left the saves %v", counts)
+	}
+
+	// Finishing a resumed game removes its save, and only its save.
+	m = New()
+	if _, err := m.resume(id); err != nil {
+		t.Fatal(err)
+	}
+	send(m, over(game.Won))
+	counts := savedCounts(t)
+	if _, ok := counts[id]; ok || len(counts) != 1 {
+		t.Errorf("finishing the resumed game left the saves %v", counts)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	m := send(NewPlaying(counterGame, nil, counterGame.New, 1), press("+"))
+	m.started = m.started.Add(-time.Minute)
+	send(m, over(game.Quit))
+
+	files, _ := saves.List()
+	if len(files) != 1 || files[0].Played < time.Minute {
+		t.Fatalf("the save doesn't say the game was played for a minute: %+v", files)
+	}
+
+	m = New()
+	if _, err := m.resume(files[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	m = send(m, over(game.Won))
+	if m.statsErr != nil {
+		t.Fatal(m.statsErr)
+	}
+
+	sessions, _ := stats.Load()
+	if len(sessions) != 2 || !sessions[0].Saved || sessions[1].Saved {
+		t.Fatalf("expected a saved session, then a finished one: %+v", sessions)
+	}
+	if d := sessions[1].GameDuration(); d < time.Minute {
+		t.Errorf("the resumed game took %v, want the minute before it was saved too", d)
+	}
+	if sum := stats.Summarize(sessions)[0]; sum.Played != 1 || sum.Rate("won") != 1 {
+		t.Errorf("the saved game counts as %d games with the outcomes %v, want one win", sum.Played, sum.Outcomes)
+	}
+
+	// A log that can't be written is shown on the results screen.
+	os.Remove(filepath.Join(dir, "gg", "sessions.jsonl"))
+	os.Mkdir(filepath.Join(dir, "gg", "sessions.jsonl"), 0o755)
+	m = send(NewPlaying(counterGame, nil, counterGame.New, 2), over(game.Lost))
+	if m.statsErr == nil || !strings.Contains(m.View(), "couldn't add the game to the statistics") {
+		t.Errorf("a log that couldn't be written wasn't shown: %v", m.statsErr)
+	}
+}
+
+func TestViewCrash(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	c := crash.Catch(NewPlaying(counterGame, []string{"counter"}, counterGame.New, 7))
+	m, _ := c.Update(press("+"))
+	m, _ = m.Update(press("!"))
+	if view := m.View(); !strings.Contains(view, "gg crashed") {
+		t.Errorf("a game that panicked in View drew %q", view)
+	}
+	if _, cmd := m.Update(press("x")); cmd == nil {
+		t.Error("the key after the crash didn't quit")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("the key after the crash didn't quit")
+	}
+
+	var err *crash.Error
+	if !errors.As(c.Err(), &err) || err.Err != nil {
+		t.Fatalf("the crash ended with %v", c.Err())
+	}
+	data, _ := os.ReadFile(err.Report)
+	report := string(data)
+	for _, want := range []string{"counter broke", "game:    counter", "seed:    7", "n: 1"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("the report is missing %q:\n%s", want, report)
+		}
+	}
+}
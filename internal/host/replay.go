@@ -0,0 +1,166 @@
+package host
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/crash"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/replay"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// player feeds the events of a replay to the game in place of the player's
+// keys and the game's clocks.
+type player struct {
+	file  *replay.File
+	next  int // The index of the next event to play.
+	speed int
+	step  bool // Whether events are played one key press at a time.
+	done  bool
+	err   error
+}
+
+// replayMsg asks for the event at index next to be played. It is dropped if
+// that event was already played, e.g. by stepping.
+type replayMsg struct {
+	next int
+}
+
+// NewReplay returns a model that plays the replay f back on model, a game of
+// d built the same way as the recorded one. speed is 1 or 2; if step is set,
+// events are played one at a time instead.
+func NewReplay(d game.Descriptor, model tea.Model, f *replay.File, speed int, step bool) Model {
+	return Model{
+		state:   playing,
+		theme:   theme.Current(),
+		current: d,
+		seed:    f.Seed,
+		game:    model,
+		history: crash.NewHistory(),
+		player: &player{
+			file:  f,
+			speed: speed,
+			step:  step,
+		},
+	}
+}
+
+func (m Model) updateReplay(msg tea.Msg) (tea.Model, tea.Cmd) {
+	p := m.player
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "1":
+			p.speed = 1
+		case "2":
+			p.speed = 2
+		case "s":
+			p.step = !p.step
+			return m, p.wait()
+		case " ", "n":
+			if p.step {
+				return m.playEvent()
+			}
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		// The recorded sizes are played back instead.
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case replayMsg:
+		if msg.next != p.next || p.step {
+			return m, nil
+		}
+		return m.playEvent()
+
+	case game.TickMsg:
+		// The recorded ticks are played back instead.
+		return m, nil
+
+	case game.OverMsg:
+		p.done = true
+		m.result = msg.Result
+		return m, nil
+	}
+
+	if p.done {
+		return m, nil
+	}
+
+	m.history.Add(msg)
+	var cmd tea.Cmd
+	m.game, cmd = m.game.Update(msg)
+	return m, cmd
+}
+
+// playEvent passes the next event to the game.
+func (m Model) playEvent() (tea.Model, tea.Cmd) {
+	p := m.player
+	if p.done || p.next >= len(p.file.Events) {
+		return m, nil
+	}
+
+	msg, err := p.file.Events[p.next].Msg(m.current)
+	if err != nil {
+		p.err = err
+		p.done = true
+		return m, nil
+	}
+	p.next++
+
+	m.history.Add(msg)
+	var cmd tea.Cmd
+	m.game, cmd = m.game.Update(msg)
+	return m, tea.Batch(cmd, p.wait())
+}
+
+// wait returns a command that asks for the next event once it is due.
+func (p *player) wait() tea.Cmd {
+	if p == nil || p.step || p.done || p.next >= len(p.file.Events) {
+		return nil
+	}
+
+	var prev time.Duration
+	if p.next > 0 {
+		prev = p.file.Events[p.next-1].At
+	}
+
+	next := p.next
+	delay := (p.file.Events[next].At - prev) / time.Duration(p.speed)
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return replayMsg{next: next}
+	})
+}
+
+func (m Model) replayView() string {
+	p := m.player
+	s := m.game.View() + "\n\n"
+
+	switch {
+	case p.err != nil:
+		s += m.theme.Error.Render("Error: couldn't play the replay: "+p.err.Error()) + "\n"
+	case p.done || p.next >= len(p.file.Events):
+		s += titleStyle.Render("replay finished") + "\n"
+		if m.result.Summary != "" {
+			s += m.result.Summary + "\n"
+		}
+	}
+
+	mode := fmt.Sprintf("%dx", p.speed)
+	if p.step {
+		mode = "step"
+	}
+	s += seedStyle.Render(fmt.Sprintf("replay %d/%d  %s  seed: %d", p.next, len(p.file.Events), mode, m.seed)) + "\n"
+	s += seedStyle.Render("1/2: speed  s: step  space: next  q: quit")
+
+	return s
+}
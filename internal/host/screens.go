@@ -0,0 +1,316 @@
+package host
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/achievements"
+	"github.com/Kaamkiya/gg/internal/daily"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/inspect"
+	"github.com/Kaamkiya/gg/internal/menu"
+	"github.com/Kaamkiya/gg/internal/saves"
+	"github.com/Kaamkiya/gg/internal/stats"
+	"github.com/Kaamkiya/gg/internal/storage"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true)
+	seedStyle  = lipgloss.NewStyle().Faint(true)
+)
+
+// Menu options that resume a saved game or start a daily challenge have
+// their ID prefixed by these.
+const (
+	resumePrefix = "resume:"
+	dailyPrefix  = "daily:"
+)
+
+// profileOption is the menu option that switches to another profile, and
+// newProfileOption the option of the profile picker that creates one.
+// statsOption and achievementsOption show the statistics and achievements.
+const (
+	profileOption      = "profile:"
+	newProfileOption   = "new:"
+	statsOption        = "stats:"
+	achievementsOption = "achievements:"
+)
+
+// newMenu returns the menu. Saved games and daily challenges come before
+// the games, so picking up where you left off is quick, and the other
+// screens after them.
+func (m Model) newMenu() menu.Model {
+	cont := menu.Section{Title: "Continue"}
+	saved, _ := saves.List()
+	for _, f := range saved {
+		d, ok := game.Lookup(f.Game)
+		if !ok || d.Resume == nil {
+			continue
+		}
+
+		cont.Items = append(cont.Items, menu.Item{
+			Label:       fmt.Sprintf("resume %s (saved %s)", d.Name, f.Saved.Format("Jan 2 15:04")),
+			Value:       resumePrefix + f.ID,
+			Game:        d.ID,
+			Description: "Pick up where you left off.",
+		})
+	}
+
+	challenges := menu.Section{Title: "Daily challenges"}
+	today := daily.Today()
+	for _, d := range game.All() {
+		if d.Daily {
+			challenges.Items = append(challenges.Items, menu.Item{
+				Label:       dailyLabel(d, today),
+				Value:       dailyPrefix + d.ID,
+				Game:        d.ID,
+				Description: "Today's puzzle, the same for everyone.",
+			})
+		}
+	}
+
+	more := menu.Section{Title: "More", Items: []menu.Item{
+		{Label: "statistics", Value: statsOption, Description: "How much you played each game, and how it went."},
+		{Label: "achievements", Value: achievementsOption, Description: "What you unlocked so far, and what's left to aim for."},
+	}}
+	if !m.fixedProfile {
+		more.Items = append(more.Items, menu.Item{
+			Label:       "profile: " + storage.Profile(),
+			Value:       profileOption,
+			Description: "Switch to another player's scores, saves and settings.",
+		})
+	}
+
+	return menu.New(m.theme, []menu.Section{cont, challenges}, []menu.Section{more})
+}
+
+// dailyLabel describes the daily challenge for d, and how it went if it was
+// already played today.
+func dailyLabel(d game.Descriptor, today string) string {
+	label := "daily " + d.Name
+
+	r, _ := daily.Load(d.ID)
+	if e, ok := r.Days[today]; ok {
+		label += " - done, " + describeEntry(e)
+	}
+	if streak := r.CurrentStreak(today); streak > 0 {
+		label += fmt.Sprintf(" (streak: %d)", streak)
+	}
+
+	return label
+}
+
+func describeEntry(e daily.Entry) string {
+	s := e.Time.String()
+	if e.Moves > 0 {
+		s += fmt.Sprintf(", %d moves", e.Moves)
+	}
+	if !e.Won {
+		s += ", not solved"
+	}
+	return s
+}
+
+// newProfiles returns the profile picker. Choosing to make a new profile
+// asks for its name.
+func newProfiles() *huh.Form {
+	var options []huh.Option[string]
+	names, _ := storage.Profiles()
+	for _, name := range names {
+		options = append(options, huh.NewOption(name, name))
+	}
+	options = append(options, huh.NewOption("new profile...", newProfileOption))
+
+	choice := new(string)
+	*choice = storage.Profile()
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Key("profile").
+				Title("gg - a tui for small offline games\n\nwho's playing?").
+				Description("Each profile keeps its own scores, saves and settings.").
+				Options(options...).
+				Value(choice),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Key("name").
+				Title("name of the new profile:").
+				Validate(storage.CheckProfile),
+		).WithHideFunc(func() bool { return *choice != newProfileOption }),
+	).WithShowHelp(false)
+}
+
+// newPage returns the screen the menu option shows, the statistics or the
+// achievements, sized to the terminal.
+func (m Model) newPage(option string) viewport.Model {
+	width, height := m.width, m.height
+	if width == 0 && height == 0 {
+		width, height = 80, 24
+	}
+
+	var s string
+	switch option {
+	case statsOption:
+		if sessions, err := stats.Load(); err != nil {
+			s = m.theme.Error.Render("Error: couldn't read the statistics: " + err.Error())
+		} else {
+			s = stats.Render(sessions, width, m.theme)
+		}
+	case achievementsOption:
+		if r, err := achievements.Load(); err != nil {
+			s = m.theme.Error.Render("Error: couldn't read the achievements: " + err.Error())
+		} else {
+			s = achievements.Render(r, m.theme)
+		}
+	}
+
+	// Leave a line for the help below.
+	v := viewport.New(width, max(height-1, 1))
+	v.SetContent(s)
+	return v
+}
+
+func newResults() *huh.Form {
+	return huh.NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Key("action").
+			Options(
+				huh.NewOption("play again", "again"),
+				huh.NewOption("back to menu", "menu"),
+				huh.NewOption("quit", "quit"),
+			),
+	)).WithShowHelp(false)
+}
+
+func (m Model) resultsView() string {
+	s := m.lastView + "\n\n"
+
+	title := m.current.Name
+	switch {
+	case m.result.Outcome == game.Won && m.current.Players == 1:
+		title += " - you won!"
+	case m.result.Outcome == game.Won, m.result.Outcome == game.Lost:
+		title += " - game over"
+	case m.result.Outcome == game.Draw:
+		title += " - it's a draw"
+	}
+	s += titleStyle.Render(title) + "\n"
+
+	if m.result.Summary != "" {
+		s += m.result.Summary + "\n"
+	}
+
+	if m.daily != "" {
+		s += seedStyle.Render("daily challenge for "+m.daily) + "\n"
+	} else {
+		s += seedStyle.Render(fmt.Sprintf("seed: %d", m.seed)) + "\n"
+	}
+
+	if m.dailyErr != nil {
+		s += m.theme.Error.Render("Error: couldn't record the daily challenge: "+m.dailyErr.Error()) + "\n"
+	} else if m.record != nil {
+		e := m.record.Days[m.daily]
+		s += fmt.Sprintf("Daily challenge: %s. Streak: %d (best %d).\n", describeEntry(e), m.record.Streak, m.record.BestStreak)
+		if !e.Won {
+			s += "Only the first try of a day counts, so the next challenge is tomorrow.\n"
+		}
+	}
+
+	if m.replayErr != nil {
+		s += m.theme.Error.Render("Error: couldn't save the replay: "+m.replayErr.Error()) + "\n"
+	} else if m.replayPath != "" {
+		s += seedStyle.Render("watch it again with: gg replay "+m.replayPath) + "\n"
+	}
+
+	if m.saveErr != nil {
+		s += m.theme.Error.Render("Error: couldn't save the game: "+m.saveErr.Error()) + "\n"
+	} else if m.saved {
+		s += "Your progress was saved. Resume it from the menu.\n"
+	}
+
+	if m.statsErr != nil {
+		s += m.theme.Error.Render("Error: couldn't add the game to the statistics: "+m.statsErr.Error()) + "\n"
+	}
+
+	s += "\n" + m.results.View()
+
+	return s
+}
+
+// toastStyle is the style of the toast that announces an unlocked
+// achievement.
+var toastStyle = lipgloss.NewStyle().Bold(true).Reverse(true).Padding(0, 1)
+
+// withToast draws the oldest toast over the first line of view, which is
+// usually empty when the view is centered.
+func (m Model) withToast(view string) string {
+	if len(m.toasts) == 0 {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	width := max(m.width, lipgloss.Width(lines[0]))
+	toast := toastStyle.Render("★ Achievement unlocked: " + m.toasts[0].Name)
+	lines[0] = lipgloss.PlaceHorizontal(width, lipgloss.Center, toast)
+
+	return strings.Join(lines, "\n")
+}
+
+// tooSmallView replaces the game while the terminal is too small for it.
+func (m Model) tooSmallView() string {
+	w, h := lipgloss.Size(m.game.View())
+
+	s := titleStyle.Render("The terminal is too small") + "\n\n"
+	s += fmt.Sprintf("%s needs %dx%d, but the terminal is %dx%d. Make it bigger to carry on.", m.current.Name, w, h, m.width, m.height)
+	if canFit(m.current) {
+		s += fmt.Sprintf("\n\nOr start the game with gg %s --fit, to size it to the terminal.", m.current.ID)
+	}
+	s += "\n\n" + seedStyle.Render("ctrl+c: quit")
+
+	return lipgloss.NewStyle().Width(m.width).Render(s)
+}
+
+// canFit reports whether d can size its playing field to the terminal,
+// which games offer with a --fit flag.
+func canFit(d game.Descriptor) bool {
+	if d.Flags == nil {
+		return false
+	}
+
+	fs := flag.NewFlagSet(d.ID, flag.ContinueOnError)
+	d.Flags(fs)
+	return fs.Lookup("fit") != nil
+}
+
+// inspectorWidth is the narrowest the inspector gets. It takes a third of
+// the terminal if that is wider.
+const inspectorWidth = 40
+
+var inspectorStyle = lipgloss.NewStyle().
+	Border(lipgloss.NormalBorder(), false, false, false, true).
+	BorderForeground(lipgloss.Color("8")).
+	PaddingLeft(1)
+
+// withInspector draws the inspector, which shows the state of the game, to
+// the right of view.
+func (m Model) withInspector(view string) string {
+	width := max(m.width/3, inspectorWidth)
+
+	s := titleStyle.Render("Inspector") + seedStyle.Render("  f12: close") + "\n\n"
+	s += inspect.Format(m.game)
+	pane := inspectorStyle.Width(width - 1).MaxHeight(m.height).Render(s)
+
+	if m.width == 0 && m.height == 0 {
+		return lipgloss.JoinHorizontal(lipgloss.Top, view, "  ", pane)
+	}
+
+	view = lipgloss.Place(max(m.width-width, 0), m.height, lipgloss.Center, lipgloss.Center, view)
+	return lipgloss.JoinHorizontal(lipgloss.Top, view, pane)
+}
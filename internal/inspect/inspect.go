@@ -0,0 +1,213 @@
+// Package inspect helps to find out what a game is doing: it logs the
+// messages the models handle, when gg runs with --debug, and describes the
+// state of a model for the inspector and crash reports.
+package inspect
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// enabled is whether gg runs with --debug.
+var enabled bool
+
+// Enable logs to the file at path from now on, and turns on the inspector.
+// The returned file is to be closed once gg is done.
+func Enable(path string) (io.Closer, error) {
+	f, err := tea.LogToFile(path, "gg")
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	enabled = true
+	return f, nil
+}
+
+// Enabled reports whether gg runs with --debug.
+func Enabled() bool {
+	return enabled
+}
+
+// maxValue is how much of a message is logged, or of a value from outside
+// gg is described.
+const maxValue = 500
+
+// Update logs msg, which model took to handle.
+func Update(model string, msg tea.Msg, took time.Duration) {
+	if !enabled {
+		return
+	}
+
+	slog.Debug("update", "model", model, "msg", fmt.Sprintf("%T", msg), "value", short(fmt.Sprintf("%+v", msg)), "took", took)
+}
+
+// short cuts s down to maxValue bytes.
+func short(s string) string {
+	if len(s) > maxValue {
+		return s[:maxValue] + "..."
+	}
+	return s
+}
+
+// skipped are the packages whose types are left out when a model is
+// described: the styles, key bindings and overlay every game has take
+// kilobytes and hardly ever have anything to do with what it is doing.
+var skipped = []string{
+	"github.com/charmbracelet/lipgloss",
+	"github.com/Kaamkiya/gg/internal/keys",
+	"github.com/Kaamkiya/gg/internal/overlay",
+	"github.com/Kaamkiya/gg/internal/theme",
+}
+
+// module is the prefix of gg's own packages, whose structs are described a
+// field at a time. Other structs are described the way fmt prints them.
+const module = "github.com/Kaamkiya/gg/"
+
+// maxDepth is how deep into nested values Format goes.
+const maxDepth = 8
+
+// Format describes v over several lines, a field, element or map entry at a
+// time. Grids, slices of slices like a sudoku or a game of life, are drawn a
+// row per line.
+func Format(v any) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%T", v)
+	format(&b, reflect.ValueOf(v), 0)
+	b.WriteString("\n")
+	return b.String()
+}
+
+func format(b *strings.Builder, v reflect.Value, depth int) {
+	if !v.IsValid() {
+		b.WriteString(" nil")
+		return
+	}
+
+	indent := "\n" + strings.Repeat("  ", depth+1)
+	if depth == maxDepth {
+		fmt.Fprintf(b, " %s", short(fmt.Sprintf("%+v", v)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString(" nil")
+			return
+		}
+		format(b, v.Elem(), depth)
+	case reflect.Struct:
+		t := v.Type()
+		if !strings.HasPrefix(t.PkgPath(), module) && t.Name() != "" {
+			fmt.Fprintf(b, " %s", short(fmt.Sprintf("%+v", v)))
+			return
+		}
+		for i := range v.NumField() {
+			f := t.Field(i)
+			if isSkipped(f.Type) {
+				continue
+			}
+			fmt.Fprintf(b, "%s%s:", indent, f.Name)
+			format(b, v.Field(i), depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString(" nil")
+			return
+		}
+		if isFlat(v.Type().Elem()) {
+			fmt.Fprintf(b, " %s", flat(v))
+			return
+		}
+		if isGrid(v.Type().Elem()) {
+			for i := range v.Len() {
+				fmt.Fprintf(b, "%s%s", indent, flat(v.Index(i)))
+			}
+			return
+		}
+		if v.Len() == 0 {
+			b.WriteString(" []")
+			return
+		}
+		for i := range v.Len() {
+			fmt.Fprintf(b, "%s[%d]:", indent, i)
+			format(b, v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if v.Len() == 0 {
+			b.WriteString(" map[]")
+			return
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+		for _, k := range keys {
+			fmt.Fprintf(b, "%s%v:", indent, k)
+			format(b, v.MapIndex(k), depth+1)
+		}
+	case reflect.String:
+		fmt.Fprintf(b, " %q", v)
+	case reflect.Func, reflect.Chan:
+		fmt.Fprintf(b, " %s", v.Type())
+	default:
+		fmt.Fprintf(b, " %v", v)
+	}
+}
+
+// isFlat reports whether slices of t fit on a line.
+func isFlat(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// flat describes v, a slice or array of numbers. Runes, like the walls of a
+// maze, are shown as the characters they are if they can be printed.
+func flat(v reflect.Value) string {
+	if v.Type().Elem().Kind() != reflect.Int32 {
+		return fmt.Sprint(v)
+	}
+
+	var s strings.Builder
+	for i := range v.Len() {
+		r := rune(v.Index(i).Int())
+		if !unicode.IsPrint(r) {
+			return fmt.Sprint(v)
+		}
+		s.WriteRune(r)
+	}
+	return strconv.Quote(s.String())
+}
+
+// isGrid reports whether slices of t are grids, drawn a row per line.
+func isGrid(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && isFlat(t.Elem())
+}
+
+func isSkipped(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	for _, p := range skipped {
+		if t.PkgPath() == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package inspect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/theme"
+)
+
+type point struct{ x, y int }
+
+type model struct {
+	grid   [][]int
+	maze   [2][3]rune
+	cursor point
+	body   []point
+	name   string
+	scores map[string]int
+	next   *point
+	theme  theme.Theme
+}
+
+func TestFormat(t *testing.T) {
+	m := model{
+		grid:   [][]int{{1, 2}, {3, 4}},
+		maze:   [2][3]rune{{'#', ' ', '#'}, {'#', 'E', '#'}},
+		cursor: point{1, 2},
+		body:   []point{{0, 0}},
+		name:   "ann",
+		scores: map[string]int{"b": 2, "a": 1},
+		theme:  theme.Current(),
+	}
+
+	want := `inspect.model
+  grid:
+    [1 2]
+    [3 4]
+  maze:
+    "# #"
+    "#E#"
+  cursor:
+    x: 1
+    y: 2
+  body:
+    [0]:
+      x: 0
+      y: 0
+  name: "ann"
+  scores:
+    a: 1
+    b: 2
+  next: nil
+`
+	if got := Format(m); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := Format(&m); !strings.HasPrefix(got, "*inspect.model\n  grid:") {
+		t.Errorf("a pointer to the model was described as\n%s", got)
+	}
+}
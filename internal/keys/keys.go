@@ -0,0 +1,220 @@
+// Package keys maps the actions of each game to keys, which players can
+// change in a config file.
+//
+// The file is keys.json in gg's config directory. It maps game IDs to
+// actions to the keys that trigger them, replacing the game's defaults:
+//
+//	{
+//		"snake": {"move-left": ["a"], "move-right": ["d"]},
+//		"pong": {"quit": ["esc"]}
+//	}
+//
+// An empty list of keys turns an action off.
+package keys
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Actions shared by several games. Games are free to define their own.
+const (
+	Left    = "move-left"
+	Right   = "move-right"
+	Up      = "move-up"
+	Down    = "move-down"
+	Drop    = "drop"
+	Restart = "restart"
+	Quit    = "quit"
+
+	// Handled by the overlay games draw over themselves. See package
+	// overlay.
+	Pause    = "pause"
+	ShowHelp = "help"
+)
+
+// Default is the binding of an action when the config doesn't change it.
+type Default struct {
+	Action string
+	Keys   []string
+	Help   string // What the action does, e.g. "left".
+	Label  string // Optional. Shown instead of the keys in the help footer.
+}
+
+// Arrows returns the defaults for moving in four directions: the arrow
+// keys, and hjkl like in vim.
+func Arrows() []Default {
+	return []Default{
+		{Action: Up, Keys: []string{"up", "k"}, Help: "up"},
+		{Action: Down, Keys: []string{"down", "j"}, Help: "down"},
+		{Action: Left, Keys: []string{"left", "h"}, Help: "left"},
+		{Action: Right, Keys: []string{"right", "l"}, Help: "right"},
+	}
+}
+
+// Config maps game IDs to actions to keys.
+type Config map[string]map[string][]string
+
+// ReadConfig reads the config file. A missing file is an empty config.
+func ReadConfig() (Config, error) {
+	dir, err := storage.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "keys.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Map holds the bindings of a game.
+type Map struct {
+	actions  []string // In the order they are shown in the help footer.
+	bindings map[string]key.Binding
+	width    int // The widest the help footer may be, or 0 for no limit.
+}
+
+// For returns the bindings of game: defaults, changed by the config file. A
+// config that can't be read is ignored, so the game is still playable; the
+// menu reports the error.
+func For(game string, defaults ...Default) Map {
+	c, _ := ReadConfig()
+	return c.For(game, defaults...)
+}
+
+// For returns the bindings of game: defaults, changed by c.
+func (c Config) For(game string, defaults ...Default) Map {
+	m := Map{bindings: map[string]key.Binding{}}
+
+	for _, d := range defaults {
+		keys, label := d.Keys, d.Label
+		if k, ok := c[game][d.Action]; ok {
+			keys, label = k, ""
+		}
+
+		m.actions = append(m.actions, d.Action)
+		m.bindings[d.Action] = newBinding(keys, label, d.Help)
+	}
+
+	return m
+}
+
+func newBinding(keys []string, label, help string) key.Binding {
+	if label == "" {
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = name(k)
+		}
+		label = strings.Join(names, "/")
+	}
+
+	b := key.NewBinding(key.WithKeys(keys...), key.WithHelp(label, help))
+	if len(keys) == 0 {
+		b.SetEnabled(false)
+	}
+	return b
+}
+
+// name returns how k is shown in the help footer.
+func name(k string) string {
+	switch k {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case " ":
+		return "space"
+	}
+	return k
+}
+
+// Matches reports whether msg triggers action.
+func (m Map) Matches(msg tea.KeyMsg, action string) bool {
+	return key.Matches(msg, m.bindings[action])
+}
+
+// Except removes the keys for which reserved is true from every binding,
+// for games that need those keys for something else, like typing letters.
+func (m Map) Except(reserved func(k string) bool) Map {
+	out := Map{actions: m.actions, bindings: map[string]key.Binding{}, width: m.width}
+
+	for action, b := range m.bindings {
+		var keys []string
+		for _, k := range b.Keys() {
+			if !reserved(k) {
+				keys = append(keys, k)
+			}
+		}
+
+		label := b.Help().Key
+		if len(keys) != len(b.Keys()) {
+			label = ""
+		}
+		out.bindings[action] = newBinding(keys, label, b.Help().Desc)
+	}
+
+	return out
+}
+
+// Label returns the keys of action as shown in the help footer, or "" if
+// the action has no keys.
+func (m Map) Label(action string) string {
+	b, ok := m.bindings[action]
+	if !ok || !b.Enabled() {
+		return ""
+	}
+	return b.Help().Key
+}
+
+// Fit returns m with a help footer no wider than width. Bindings that don't
+// fit are left out.
+func (m Map) Fit(width int) Map {
+	m.width = width
+	return m
+}
+
+// Help returns a footer listing the bindings.
+func (m Map) Help() string {
+	h := help.New()
+	h.Width = m.width
+	return h.ShortHelpView(m.ordered())
+}
+
+// FullHelp returns every binding, one per line.
+func (m Map) FullHelp() string {
+	return help.New().FullHelpView([][]key.Binding{m.ordered()})
+}
+
+// ordered returns the bindings in the order of m.actions.
+func (m Map) ordered() []key.Binding {
+	bindings := make([]key.Binding, len(m.actions))
+	for i, action := range m.actions {
+		bindings[i] = m.bindings[action]
+	}
+	return bindings
+}
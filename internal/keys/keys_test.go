@@ -0,0 +1,93 @@
+package keys
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func press(k string) tea.KeyMsg {
+	switch k {
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	config := `{"snake": {"move-left": ["a"], "quit": []}}`
+	if err := os.MkdirAll(filepath.Join(dir, "gg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gg", "keys.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := []Default{
+		{Action: Left, Keys: []string{"left", "h"}, Help: "left"},
+		{Action: Right, Keys: []string{"right", "l"}, Help: "right"},
+		{Action: Quit, Keys: []string{"q"}, Help: "quit"},
+	}
+
+	m := For("snake", defaults...)
+	if !m.Matches(press("a"), Left) || m.Matches(press("h"), Left) {
+		t.Error("expected the config to replace the keys for move-left")
+	}
+	if !m.Matches(press("l"), Right) {
+		t.Error("expected the default keys for move-right")
+	}
+	if m.Matches(press("q"), Quit) {
+		t.Error("expected quit to be turned off")
+	}
+	if help := m.Help(); !strings.Contains(help, "a left") || strings.Contains(help, "quit") {
+		t.Errorf("unexpected help %q", help)
+	}
+
+	// Other games keep their defaults.
+	if !For("dodger", defaults...).Matches(press("left"), Left) {
+		t.Error("expected dodger to keep the default keys")
+	}
+}
+
+func TestExcept(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	letter := func(k string) bool {
+		return len(k) == 1 && unicode.IsLetter(rune(k[0]))
+	}
+
+	m := For("hangman", Default{Action: Quit, Keys: []string{"q", "esc"}, Help: "quit"}).Except(letter)
+	if m.Matches(press("q"), Quit) {
+		t.Error("expected q to be left for guessing")
+	}
+	if !m.Matches(press("esc"), Quit) {
+		t.Error("expected esc to still quit")
+	}
+}
+
+func TestFit(t *testing.T) {
+	m := Config{}.For("snake", Arrows()...)
+
+	full := m.Help()
+	if !strings.Contains(full, "right") {
+		t.Fatalf("expected every binding without a limit, got %q", full)
+	}
+
+	fitted := m.Fit(30).Help()
+	if w := lipgloss.Width(fitted); w > 30 {
+		t.Errorf("expected the footer to fit in 30 columns, got %d: %q", w, fitted)
+	}
+	if !strings.Contains(fitted, "up") {
+		t.Errorf("expected the first bindings to be kept, got %q", fitted)
+	}
+}
@@ -0,0 +1,79 @@
+package menu
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+func favouritesPath() (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "favourites.json"), nil
+}
+
+func readFavourites(p string) ([]string, error) {
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Favourites returns the IDs of the games the current profile pinned to the
+// top of the menu, in the order they were pinned.
+func Favourites() ([]string, error) {
+	p, err := favouritesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return readFavourites(p)
+}
+
+// SetFavourite pins the game with the given ID to the top of the menu, or
+// unpins it if on is false.
+func SetFavourite(id string, on bool) error {
+	p, err := favouritesPath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := storage.Lock(p)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ids, err := readFavourites(p)
+	if err != nil {
+		return err
+	}
+
+	ids = slices.DeleteFunc(ids, func(f string) bool { return f == id })
+	if on {
+		ids = append(ids, id)
+	}
+
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFile(p, data)
+}
@@ -0,0 +1,488 @@
+// Package menu is the launcher gg starts in. It lists the games by
+// category, after the player's favourites and the games they played last,
+// with a search, a filter on the amount of players and a panel describing
+// the highlighted game.
+//
+// The host keeps a Model and passes it every message while the menu is
+// shown, much like a game's overlay:
+//
+//	m.menu, res = m.menu.Update(msg)
+//	switch res {
+//	case menu.Chosen:
+//		// Start m.menu.Choice().
+//	case menu.Quit:
+//		return m, tea.Quit
+//	}
+package menu
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/stats"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Item is something the player can choose from the menu.
+type Item struct {
+	Label string
+	Value string // What Choice returns once the item is chosen.
+
+	// Game is the ID of the game the item starts or resumes, if any. The
+	// panel describes it, and the player filter hides it if the game is
+	// for another amount of players.
+	Game string
+
+	// Description fills the panel instead of the game's description.
+	Description string
+}
+
+// Section is a titled group of items.
+type Section struct {
+	Title string
+	Items []Item
+}
+
+// Result tells the host what the player did with a message.
+type Result int
+
+const (
+	None   Result = iota // Nothing to act on yet.
+	Chosen               // The player chose an item; see Choice.
+	Quit                 // The player wants to leave gg.
+)
+
+// recentGames is how many of the games played last are listed.
+const recentGames = 3
+
+// categories are the sections the games are grouped in, in order.
+var categories = []game.Category{game.Puzzle, game.Arcade, game.Board, game.Word}
+
+// row is an item as it is listed, under the title of its section.
+type row struct {
+	section string
+	item    Item
+}
+
+// Model is the state of the menu.
+type Model struct {
+	theme       theme.Theme
+	first, last []Section
+
+	favourites []string
+	recent     []string
+	played     map[string]played
+	best       map[string]int // The best score of each game with one.
+
+	rows      []row // What is listed, after the search and the filter.
+	cursor    int
+	query     string
+	searching bool
+	players   int // Only games for this many players are listed, or every game if 0.
+	err       error
+
+	width, height int
+	choice        string
+}
+
+// played sums up how much the player played a game.
+type played struct {
+	times int
+	last  time.Time
+}
+
+// New returns the menu. first and last are sections of other things to do,
+// listed before and after the games, like resuming a saved game or the
+// statistics.
+func New(t theme.Theme, first, last []Section) Model {
+	m := Model{theme: t, first: first, last: last, played: map[string]played{}, best: map[string]int{}}
+
+	m.favourites, m.err = Favourites()
+
+	// Like scores, a log that can't be read isn't worth bothering the
+	// player about here; the statistics screen says what is wrong.
+	sessions, _ := stats.Load()
+	for i := len(sessions) - 1; i >= 0; i-- {
+		s := sessions[i]
+		if _, ok := game.Lookup(s.Game); !ok {
+			continue
+		}
+
+		// The log is oldest first, so the first session of a game found is
+		// its last.
+		p := m.played[s.Game]
+		if p.times == 0 {
+			p.last = s.End
+			if len(m.recent) < recentGames {
+				m.recent = append(m.recent, s.Game)
+			}
+		}
+		p.times++
+		m.played[s.Game] = p
+	}
+
+	for _, d := range game.All() {
+		if d.Score == "" {
+			continue
+		}
+		if best, _ := scores.Best(d.ID); best > 0 {
+			m.best[d.ID] = best
+		}
+	}
+
+	m.refresh()
+	return m
+}
+
+// Choice returns the value of the item the player chose.
+func (m Model) Choice() string {
+	return m.choice
+}
+
+// sections returns every section, before the search and the filter.
+func (m Model) sections() []Section {
+	sections := slices.Clone(m.first)
+
+	favourites := Section{Title: "Favourites"}
+	for _, id := range m.favourites {
+		if d, ok := game.Lookup(id); ok {
+			favourites.Items = append(favourites.Items, gameItem(d))
+		}
+	}
+
+	recent := Section{Title: "Recently played"}
+	for _, id := range m.recent {
+		if d, ok := game.Lookup(id); ok {
+			recent.Items = append(recent.Items, gameItem(d))
+		}
+	}
+	sections = append(sections, favourites, recent)
+
+	for _, c := range categories {
+		s := Section{Title: title(string(c))}
+		for _, d := range game.All() {
+			if d.Category == c {
+				s.Items = append(s.Items, gameItem(d))
+			}
+		}
+		sections = append(sections, s)
+	}
+
+	return append(sections, m.last...)
+}
+
+func gameItem(d game.Descriptor) Item {
+	return Item{Label: d.Name, Value: d.ID, Game: d.ID}
+}
+
+// title capitalises s.
+func title(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// refresh lists the items again after the search, the filter or the
+// favourites changed, keeping the cursor on the same item if it is still
+// listed.
+func (m *Model) refresh() {
+	var current row
+	if m.cursor < len(m.rows) {
+		current = m.rows[m.cursor]
+	}
+
+	m.rows = nil
+	if m.query != "" {
+		for _, it := range search(m.query, m.sections()) {
+			if m.shows(it) {
+				m.rows = append(m.rows, row{section: "Results", item: it})
+			}
+		}
+	} else {
+		for _, s := range m.sections() {
+			for _, it := range s.Items {
+				if m.shows(it) {
+					m.rows = append(m.rows, row{section: s.Title, item: it})
+				}
+			}
+		}
+	}
+
+	m.cursor = 0
+	for i, r := range m.rows {
+		if r.item.Value == current.item.Value && (r.section == current.section || m.query != "") {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+// shows reports whether it passes the player filter.
+func (m Model) shows(it Item) bool {
+	d, ok := game.Lookup(it.Game)
+	return m.players == 0 || !ok || d.Players == m.players
+}
+
+// Update handles msg, and reports whether the player chose an item or wants
+// to quit.
+func (m Model) Update(msg tea.Msg) (Model, Result) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+
+		switch msg.String() {
+		case "up", "k":
+			m.move(-1)
+		case "down", "j":
+			m.move(1)
+		case "home", "g":
+			m.cursor = 0
+		case "end", "G":
+			m.cursor = max(len(m.rows)-1, 0)
+		case "enter":
+			return m.choose()
+		case "/":
+			m.searching = true
+		case "f":
+			m.toggleFavourite()
+		case "tab":
+			m.cyclePlayers()
+		case "q", "esc":
+			return m, Quit
+		}
+	}
+
+	return m, None
+}
+
+// updateSearch handles the keys typed into the search.
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, Result) {
+	switch msg.Type {
+	case tea.KeyUp:
+		m.move(-1)
+	case tea.KeyDown:
+		m.move(1)
+	case tea.KeyEnter:
+		return m.choose()
+	case tea.KeyTab:
+		m.cyclePlayers()
+	case tea.KeyEsc:
+		m.searching = false
+		m.query = ""
+		m.refresh()
+	case tea.KeyBackspace:
+		if r := []rune(m.query); len(r) > 0 {
+			m.query = string(r[:len(r)-1])
+			m.refresh()
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.query += string(msg.Runes)
+		m.refresh()
+	}
+
+	return m, None
+}
+
+func (m *Model) move(by int) {
+	m.cursor = min(max(m.cursor+by, 0), max(len(m.rows)-1, 0))
+}
+
+func (m Model) choose() (Model, Result) {
+	if len(m.rows) == 0 {
+		return m, None
+	}
+
+	m.choice = m.rows[m.cursor].item.Value
+	return m, Chosen
+}
+
+// cyclePlayers moves the player filter on to the next amount of players.
+func (m *Model) cyclePlayers() {
+	m.players = (m.players + 1) % 3
+	m.refresh()
+}
+
+// toggleFavourite pins the highlighted game to the top of the menu, or
+// unpins it.
+func (m *Model) toggleFavourite() {
+	if len(m.rows) == 0 || m.rows[m.cursor].item.Game == "" {
+		return
+	}
+
+	id := m.rows[m.cursor].item.Game
+	if m.err = SetFavourite(id, !slices.Contains(m.favourites, id)); m.err != nil {
+		return
+	}
+
+	m.favourites, m.err = Favourites()
+	m.refresh()
+}
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	sectionStyle = lipgloss.NewStyle().Bold(true).Faint(true)
+	faintStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// panelWidth is the width of the panel next to the list.
+const panelWidth = 40
+
+func (m Model) View() string {
+	header := titleStyle.Render("gg - a tui for small offline games") + "\n\n"
+	switch {
+	case m.searching:
+		header += "search: " + m.query + "█"
+	case m.query != "":
+		header += "search: " + m.query
+	default:
+		header += faintStyle.Render("/ to search")
+	}
+	header += faintStyle.Render("  •  players: "+m.playersLabel()) + "\n\n"
+
+	help := "↑/↓ move • enter choose • / search • f favourite • tab players • q quit"
+	if m.searching {
+		help = "type to search • ↑/↓ move • enter choose • esc stop searching"
+	}
+	footer := "\n" + faintStyle.Render(help)
+	if m.err != nil {
+		footer = "\n" + m.theme.Error.Render("Error: "+m.err.Error()) + footer
+	}
+
+	list := m.list(m.height - lipgloss.Height(header) - lipgloss.Height(footer))
+
+	// The panel goes beside the list, or below it if there is no room.
+	body := list
+	if panel := m.panel(); panel != "" {
+		if m.width == 0 || lipgloss.Width(list)+panelWidth+4 <= m.width {
+			border := lipgloss.NewStyle().
+				Border(lipgloss.NormalBorder(), false, false, false, true).
+				BorderForeground(lipgloss.Color("8")).
+				PaddingLeft(2).
+				MarginLeft(4).
+				Width(panelWidth)
+			body = lipgloss.JoinHorizontal(lipgloss.Top, list, border.Render(panel))
+		} else {
+			body = list + "\n\n" + lipgloss.NewStyle().Width(max(m.width, 1)).Render(panel)
+		}
+	}
+
+	return header + body + "\n" + footer
+}
+
+func (m Model) playersLabel() string {
+	if m.players == 0 {
+		return "any"
+	}
+	return players(m.players)
+}
+
+// list draws the sections and their items, scrolled to keep the cursor in
+// view if they are taller than height. A height of 0 or less fits
+// everything.
+func (m Model) list(height int) string {
+	if len(m.rows) == 0 {
+		return faintStyle.Render("Nothing matches.")
+	}
+
+	var lines []string
+	cursorLine := 0
+	for i, r := range m.rows {
+		if i == 0 || r.section != m.rows[i-1].section {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, sectionStyle.Render(r.section))
+		}
+
+		label := r.item.Label
+		if r.item.Value == r.item.Game && slices.Contains(m.favourites, r.item.Game) {
+			label += " ★"
+		}
+
+		if i == m.cursor {
+			cursorLine = len(lines)
+			lines = append(lines, m.theme.Highlight.Render("> "+label))
+		} else {
+			lines = append(lines, "  "+label)
+		}
+	}
+
+	if height > 0 && len(lines) > height {
+		start := min(max(cursorLine-height/2, 0), len(lines)-height)
+		lines = lines[start : start+height]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// panel describes the highlighted item.
+func (m Model) panel() string {
+	if len(m.rows) == 0 {
+		return ""
+	}
+
+	it := m.rows[m.cursor].item
+	d, isGame := game.Lookup(it.Game)
+	if !isGame {
+		return titleStyle.Render(it.Label) + "\n\n" + it.Description
+	}
+
+	s := titleStyle.Render(d.Name) + "\n"
+	s += faintStyle.Render(fmt.Sprintf("%s • %s", d.Category, players(d.Players))) + "\n\n"
+
+	if it.Description != "" {
+		s += it.Description + "\n\n"
+	}
+	s += d.Description
+
+	var facts []string
+	if best, ok := m.best[d.ID]; ok {
+		facts = append(facts, fmt.Sprintf("best %s: %d", d.Score, best))
+	}
+	if p, ok := m.played[d.ID]; ok {
+		facts = append(facts, fmt.Sprintf("played %s, last on %s", times(p.times), p.last.Format("Jan 2")))
+	}
+	if len(facts) > 0 {
+		s += "\n\n" + strings.Join(facts, "\n")
+	}
+
+	if slices.Contains(m.favourites, d.ID) {
+		s += "\n\n" + faintStyle.Render("★ favourite • f to unpin")
+	} else {
+		s += "\n\n" + faintStyle.Render("f to pin to the top")
+	}
+
+	return s
+}
+
+func players(n int) string {
+	if n == 1 {
+		return "1 player"
+	}
+	return fmt.Sprintf("%d players", n)
+}
+
+func times(n int) string {
+	switch n {
+	case 1:
+		return "once"
+	case 2:
+		return "twice"
+	}
+	return fmt.Sprintf("%d times", n)
+}
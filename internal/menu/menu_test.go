@@ -0,0 +1,150 @@
+package menu
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/stats"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func init() {
+	for _, d := range []game.Descriptor{
+		{ID: "snake", Name: "snake", Players: 1, Category: game.Arcade, Score: "length"},
+		{ID: "sudoku", Name: "sudoku", Players: 1, Category: game.Puzzle},
+		{ID: "connect4", Name: "connect 4", Players: 2, Category: game.Board},
+	} {
+		d.New = func(*rand.Rand) tea.Model { return nil }
+		game.Register(d)
+	}
+}
+
+// start returns a menu with the statistics after the games.
+func start(t *testing.T) Model {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	return New(theme.Current(), nil, []Section{{Title: "More", Items: []Item{{Label: "statistics", Value: "stats"}}}})
+}
+
+// send sends m a key press for each of keys.
+func send(m Model, keys ...string) (Model, Result) {
+	var res Result
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		m, res = m.Update(msg)
+	}
+	return m, res
+}
+
+func labels(m Model) []string {
+	var l []string
+	for _, r := range m.rows {
+		l = append(l, r.item.Label)
+	}
+	return l
+}
+
+func TestMatch(t *testing.T) {
+	if _, ok := match("sk", "snake"); !ok {
+		t.Error(`"sk" doesn't match snake`)
+	}
+	if _, ok := match("ks", "snake"); ok {
+		t.Error(`"ks" matches snake`)
+	}
+
+	// Letters in a row and at the start of words count for more.
+	a, _ := match("c4", "connect 4")
+	b, _ := match("c4", "c4 sudoku")
+	if a >= b {
+		t.Errorf(`"c4" scores %d for connect 4 and %d for c4 sudoku`, a, b)
+	}
+}
+
+func TestMenu(t *testing.T) {
+	m := start(t)
+	if got, want := labels(m), []string{"sudoku", "snake", "connect 4", "statistics"}; !slices.Equal(got, want) {
+		t.Fatalf("listed %q, want %q", got, want)
+	}
+
+	m, res := send(m, "/", "s", "n", "enter")
+	if res != Chosen || m.Choice() != "snake" {
+		t.Errorf("searching for sn chose %q", m.Choice())
+	}
+
+	m, _ = send(m, "esc", "tab", "tab")
+	if got, want := labels(m), []string{"connect 4", "statistics"}; !slices.Equal(got, want) {
+		t.Errorf("two player games are %q, want %q", got, want)
+	}
+
+	if _, res := send(m, "q"); res != Quit {
+		t.Errorf("q gave %v, want Quit", res)
+	}
+}
+
+func TestFavourites(t *testing.T) {
+	m := start(t)
+
+	// Pin snake, the second game listed.
+	m, _ = send(m, "j", "f")
+	if ids, err := Favourites(); err != nil || !slices.Equal(ids, []string{"snake"}) {
+		t.Fatalf("Favourites() = %v, %v after pinning snake", ids, err)
+	}
+	if m.rows[0].section != "Favourites" || m.rows[0].item.Game != "snake" {
+		t.Errorf("snake isn't listed first: %+v", m.rows[0])
+	}
+
+	m, _ = send(m, "f")
+	if ids, _ := Favourites(); len(ids) != 0 {
+		t.Errorf("favourites are %v after unpinning snake", ids)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	for _, id := range []string{"snake", "sudoku", "snake"} {
+		stats.Log(stats.New(id, time.Now(), game.Result{Outcome: game.Lost}))
+	}
+
+	m := New(theme.Current(), nil, nil)
+	var recent []string
+	for _, r := range m.rows {
+		if r.section == "Recently played" {
+			recent = append(recent, r.item.Game)
+		}
+	}
+	if !slices.Equal(recent, []string{"snake", "sudoku"}) {
+		t.Errorf("recently played %v, want snake then sudoku", recent)
+	}
+	if p := m.played["snake"]; p.times != 2 {
+		t.Errorf("snake was played %d times, want 2", p.times)
+	}
+}
+
+func TestBest(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	scores.Record("snake", 12)
+
+	m := New(theme.Current(), nil, nil)
+	if best := m.best["snake"]; best != 12 {
+		t.Errorf("the best snake score is %d, want 12", best)
+	}
+
+	// The host makes a new menu when a game ends.
+	scores.Record("snake", 20)
+	if best := New(theme.Current(), nil, nil).best["snake"]; best != 20 {
+		t.Errorf("a new menu has the best snake score %d, want 20", best)
+	}
+}
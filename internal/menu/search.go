@@ -0,0 +1,88 @@
+package menu
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// match reports whether the letters of query appear in s in order, ignoring
+// case and spaces, and scores how well they do: letters in a row and letters
+// that start a word count for more.
+func match(query, s string) (int, bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	text := []rune(strings.ToLower(s))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score, next, prev := 0, 0, -2
+	for i, r := range text {
+		if next == len(q) {
+			break
+		}
+		if r != q[next] {
+			continue
+		}
+
+		score++
+		if i == prev+1 {
+			score += 4
+		}
+		if i == 0 || !unicode.IsLetter(text[i-1]) && !unicode.IsDigit(text[i-1]) {
+			score += 3
+		}
+		prev = i
+		next++
+	}
+
+	if next < len(q) {
+		return 0, false
+	}
+	return score, true
+}
+
+// search returns the items in sections that match query, best first. An
+// item in more than one section, like a favourite game, is listed once.
+func search(query string, sections []Section) []Item {
+	type result struct {
+		item  Item
+		score int
+	}
+
+	var results []result
+	seen := map[string]bool{}
+	for _, s := range sections {
+		for _, it := range s.Items {
+			if seen[it.Value] {
+				continue
+			}
+			seen[it.Value] = true
+
+			score, ok := match(query, it.Label)
+			if d, isGame := game.Lookup(it.Game); isGame {
+				// Typing a category finds its games, behind the games
+				// whose names match.
+				if _, inCategory := match(query, string(d.Category)); inCategory && !ok {
+					score, ok = 0, true
+				}
+			}
+			if ok {
+				results = append(results, result{it, score})
+			}
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b result) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	items := make([]Item, len(results))
+	for i, r := range results {
+		items[i] = r.item
+	}
+	return items
+}
@@ -0,0 +1,95 @@
+package netplay
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// protocol is the version of the messages below. Players must run versions
+// of gg that speak the same one.
+const protocol = 1
+
+// The types of message.
+const (
+	hello = "hello" // Sent by the host once the guest connects.
+	state = "state" // Sent by the host whenever the game changes.
+	move  = "move"  // Sent by the guest for each move their player makes.
+	bye   = "bye"   // Sent by either player when they leave.
+)
+
+// message is a single line sent between the players, as JSON.
+type message struct {
+	Type     string          `json:"type"`
+	Protocol int             `json:"protocol,omitempty"`
+	Game     string          `json:"game,omitempty"`
+	State    json.RawMessage `json:"state,omitempty"`
+	Move     string          `json:"move,omitempty"`
+}
+
+// writeTimeout is how long a message may take to send before the other
+// player is taken to have gone.
+const writeTimeout = 5 * time.Second
+
+// conn is the connection to the other player.
+type conn struct {
+	c   net.Conn
+	enc *json.Encoder
+	dec *json.Decoder
+}
+
+func newConn(c net.Conn) *conn {
+	return &conn{c: c, enc: json.NewEncoder(c), dec: json.NewDecoder(c)}
+}
+
+// send sends m to the other player.
+func (c *conn) send(m message) error {
+	c.c.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.enc.Encode(m)
+}
+
+// receivedMsg is a message from the other player.
+type receivedMsg struct {
+	message
+}
+
+// disconnectedMsg is sent when the connection to the other player is lost.
+type disconnectedMsg struct {
+	err error
+}
+
+// receive returns a command that waits for the next message from the other
+// player. Only one may be waiting at a time.
+func (c *conn) receive() tea.Cmd {
+	return func() tea.Msg {
+		var m message
+		if err := c.dec.Decode(&m); err != nil {
+			return disconnectedMsg{err: err}
+		}
+		return receivedMsg{m}
+	}
+}
+
+// close says goodbye to the other player and closes the connection.
+func (c *conn) close() {
+	c.send(message{Type: bye})
+	c.c.Close()
+}
+
+// connectedMsg is sent when a guest connects to the host.
+type connectedMsg struct {
+	c   net.Conn
+	err error
+}
+
+// accept returns a command that waits for a guest to connect to ln, then
+// stops listening; a game only has one guest.
+func accept(ln net.Listener) tea.Cmd {
+	return func() tea.Msg {
+		c, err := ln.Accept()
+		ln.Close()
+		return connectedMsg{c: c, err: err}
+	}
+}
@@ -0,0 +1,349 @@
+// Package netplay plays two-player games over the network, with gg host on
+// one machine and gg join on another.
+//
+// The host's game is the one that counts. The guest sends the moves their
+// player makes, and the host sends the state of the game back whenever it
+// changes, including every tick of games with a clock. Messages are JSON,
+// one per line. See game.Versus.
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/Kaamkiya/gg/internal/crash"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultPort is the port gg host listens on unless told otherwise.
+const DefaultPort = 4321
+
+// Model runs a network game, from waiting for the other player until the
+// game is over.
+type Model struct {
+	seat  game.Seat
+	d     game.Descriptor // Not known to the guest until the host says hello.
+	ln    net.Listener    // Set on the host until the guest connects.
+	conn  *conn
+	theme theme.Theme
+
+	game game.Versus // Nil until both players are connected.
+	sent string      // The last state the host sent, so unchanged states aren't sent again.
+
+	result   *game.Result // Set once the game is over.
+	lastView string       // The last frame of the game that ended.
+	err      error        // Why the game ended early, if it did.
+
+	width  int
+	height int
+}
+
+// Host returns a model that waits for a guest to connect to ln, then plays
+// d with them.
+func Host(d game.Descriptor, ln net.Listener) Model {
+	return Model{seat: game.Host, d: d, ln: ln, theme: theme.Current()}
+}
+
+// Join returns a model that plays whichever game the host on the other end
+// of c started.
+func Join(c net.Conn) Model {
+	return Model{seat: game.Guest, conn: newConn(c), theme: theme.Current()}
+}
+
+// Run runs m in a new program and blocks until the player leaves. If gg
+// panics, a crash report is written and the error is a *crash.Error.
+func Run(m Model) error {
+	c := crash.Catch(m)
+	if _, err := tea.NewProgram(c).Run(); err != nil {
+		return err
+	}
+	return c.Err()
+}
+
+// Describe adds the game being played to the report of a crash.
+func (m Model) Describe(r *crash.Report) {
+	if m.game == nil {
+		return
+	}
+
+	r.Game = m.d.ID
+	r.Model = crash.Dump(m.game)
+}
+
+func (m Model) Init() tea.Cmd {
+	if m.seat == game.Host {
+		return accept(m.ln)
+	}
+	return m.conn.receive()
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.leave()
+			return m, tea.Quit
+		}
+		if m.result != nil || m.err != nil {
+			return m, tea.Quit
+		}
+		if m.game == nil {
+			return m, nil
+		}
+
+	case connectedMsg:
+		if msg.err != nil {
+			return m.fail(fmt.Errorf("couldn't accept the other player: %w", msg.err))
+		}
+		return m.start(newConn(msg.c))
+
+	case receivedMsg:
+		return m.receive(msg.message)
+
+	case disconnectedMsg:
+		if m.result != nil {
+			return m, nil
+		}
+		if m.game == nil {
+			return m.fail(fmt.Errorf("lost the connection to the host: %w", msg.err))
+		}
+		return m.end(game.Result{Outcome: game.Quit, Summary: "the connection to the other player was lost"})
+
+	case game.OverMsg:
+		return m.end(msg.Result)
+
+	case game.MoveMsg:
+		// The guest's moves are played by the host.
+		if m.seat == game.Guest {
+			if err := m.conn.send(message{Type: move, Move: msg.Move}); err != nil {
+				return m.end(game.Result{Outcome: game.Quit, Summary: "the connection to the other player was lost"})
+			}
+			return m, nil
+		}
+	}
+
+	if m.game == nil || m.result != nil {
+		return m, nil
+	}
+	return m.updateGame(msg)
+}
+
+// updateGame passes msg to the game and, on the host, sends the guest the
+// state of the game if it changed.
+func (m Model) updateGame(msg tea.Msg) (tea.Model, tea.Cmd) {
+	g, cmd := m.game.Update(msg)
+	m.game = g.(game.Versus)
+
+	if m.seat == game.Host {
+		if err := m.sync(); err != nil {
+			return m.end(game.Result{Outcome: game.Quit, Summary: "the connection to the other player was lost"})
+		}
+	}
+	return m, cmd
+}
+
+// start says hello to the guest who just connected to the host, and starts
+// the game.
+func (m Model) start(c *conn) (tea.Model, tea.Cmd) {
+	m.conn = c
+	m.ln = nil
+	m.game = m.d.Versus(m.seat)
+
+	if err := c.send(message{Type: hello, Protocol: protocol, Game: m.d.ID}); err != nil {
+		return m.fail(fmt.Errorf("couldn't greet the other player: %w", err))
+	}
+	if err := m.sync(); err != nil {
+		return m.fail(fmt.Errorf("couldn't send the game: %w", err))
+	}
+
+	return m, tea.Batch(m.game.Init(), c.receive(), m.resize())
+}
+
+// sync sends the guest the state of the host's game, unless it is the same
+// as the last one sent.
+func (m *Model) sync() error {
+	data, err := json.Marshal(m.game.State())
+	if err != nil {
+		return err
+	}
+	if string(data) == m.sent {
+		return nil
+	}
+
+	m.sent = string(data)
+	return m.conn.send(message{Type: state, State: data})
+}
+
+// receive handles a message from the other player, and waits for the next.
+func (m Model) receive(msg message) (tea.Model, tea.Cmd) {
+	if m.result != nil {
+		return m, nil
+	}
+
+	switch {
+	case msg.Type == bye:
+		return m.end(game.Result{Outcome: game.Quit, Summary: "the other player left"})
+
+	case m.seat == game.Host && msg.Type == move:
+		model, cmd := m.updateGame(game.MoveMsg{Seat: game.Guest, Move: msg.Move})
+		return model, tea.Batch(cmd, m.conn.receive())
+
+	case m.seat == game.Guest && msg.Type == hello:
+		if msg.Protocol != protocol {
+			return m.fail(errors.New("the host runs a different version of gg"))
+		}
+		d, ok := game.Lookup(msg.Game)
+		if !ok || d.Versus == nil {
+			return m.fail(fmt.Errorf("the host started %q, which this version of gg can't play over the network", msg.Game))
+		}
+
+		m.d = d
+		m.game = d.Versus(m.seat)
+		return m, tea.Batch(m.game.Init(), m.conn.receive(), m.resize())
+
+	case m.seat == game.Guest && msg.Type == state && m.game != nil:
+		g, cmd, err := m.game.Sync(msg.State)
+		if err != nil {
+			return m.fail(fmt.Errorf("the host sent a game that can't be shown: %w", err))
+		}
+		m.game = g
+		if cmd != nil {
+			// The game is over, so there is nothing left to hear; the
+			// host's goodbye mustn't overtake the result.
+			return m, cmd
+		}
+		return m, m.conn.receive()
+	}
+
+	return m, m.conn.receive()
+}
+
+// end ends the game with result, and says goodbye to the other player.
+func (m Model) end(result game.Result) (tea.Model, tea.Cmd) {
+	if m.result != nil {
+		return m, nil
+	}
+
+	m.leave()
+	m.result = &result
+	if m.game != nil {
+		m.lastView = m.game.View()
+	}
+	return m, nil
+}
+
+// fail stops the game because of err.
+func (m Model) fail(err error) (tea.Model, tea.Cmd) {
+	m.leave()
+	m.err = err
+	return m, nil
+}
+
+// leave closes the connection to the other player, or stops waiting for
+// one.
+func (m *Model) leave() {
+	if m.ln != nil {
+		m.ln.Close()
+	}
+	if m.conn != nil && m.result == nil {
+		m.conn.close()
+	}
+}
+
+// resize repeats the last known window size, so the game learns it.
+func (m Model) resize() tea.Cmd {
+	if m.width == 0 && m.height == 0 {
+		return nil
+	}
+
+	size := tea.WindowSizeMsg{Width: m.width, Height: m.height}
+	return func() tea.Msg {
+		return size
+	}
+}
+
+func (m Model) View() string {
+	hint := lipgloss.NewStyle().Faint(true)
+	var s string
+
+	switch {
+	case m.err != nil:
+		s = m.theme.Error.Render("Error: "+m.err.Error()) + "\n\n" + hint.Render("press any key to leave")
+
+	case m.result != nil:
+		s = m.lastView + "\n\n" + m.theme.Highlight.Render(outcome(*m.result))
+		if m.result.Summary != "" {
+			s += "  " + m.result.Summary
+		}
+		s += "\n\n" + hint.Render("press any key to leave")
+
+	case m.game != nil:
+		s = m.game.View()
+
+	case m.seat == game.Host:
+		s = fmt.Sprintf("Waiting for someone to join %s...\n\n", m.d.Name)
+		s += "They can join with one of:\n\n"
+		for _, addr := range addresses(m.ln.Addr()) {
+			s += "  gg join " + addr + "\n"
+		}
+		s += "\n" + hint.Render("ctrl+c: stop waiting")
+
+	default:
+		s = "Joining...\n\n" + hint.Render("ctrl+c: stop")
+	}
+
+	if m.width == 0 && m.height == 0 {
+		return s
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, s)
+}
+
+// outcome describes how the game ended for the player.
+func outcome(r game.Result) string {
+	switch r.Outcome {
+	case game.Won:
+		return "You won!"
+	case game.Lost:
+		return "You lost."
+	case game.Draw:
+		return "It's a draw."
+	default:
+		return "Game over."
+	}
+}
+
+// addresses returns the addresses the guest can reach addr, a listener's
+// address, at: one for each network the machine is on.
+func addresses(addr net.Addr) []string {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return []string{addr.String()}
+	}
+	if !tcp.IP.IsUnspecified() {
+		return []string{tcp.String()}
+	}
+
+	port := strconv.Itoa(tcp.Port)
+	var all []string
+	ifaces, _ := net.InterfaceAddrs()
+	for _, a := range ifaces {
+		ip, ok := a.(*net.IPNet)
+		if !ok || ip.IP.To4() == nil || ip.IP.IsLoopback() || ip.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		all = append(all, net.JoinHostPort(ip.IP.String(), port))
+	}
+
+	// Last, for a second gg on the same machine.
+	return append(all, net.JoinHostPort("localhost", port))
+}
@@ -0,0 +1,160 @@
+package netplay
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/gametest"
+
+	_ "github.com/Kaamkiya/gg/internal/app/connect4"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// player runs one side of a network game, much like a tea.Program would.
+type player struct {
+	t    *testing.T
+	m    tea.Model
+	msgs chan tea.Msg
+
+	other *player
+}
+
+func newPlayer(t *testing.T, m Model) *player {
+	p := &player{t: t, m: m, msgs: make(chan tea.Msg, 16)}
+	p.run(m.Init())
+	return p
+}
+
+// run runs cmd in the background, as commands that wait on the network must.
+func (p *player) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+
+	go func() {
+		switch msg := cmd().(type) {
+		case nil:
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				p.run(cmd)
+			}
+		default:
+			p.msgs <- msg
+		}
+	}()
+}
+
+func (p *player) keys(keys ...string) {
+	for _, k := range keys {
+		var cmd tea.Cmd
+		p.m, cmd = p.m.Update(gametest.Key(k))
+		p.run(cmd)
+	}
+}
+
+// play waits for the player's turn, then drops a piece in col and waits for
+// the host to play it.
+func (p *player) play(col string) {
+	p.t.Helper()
+
+	p.waitFor("Your turn")
+	p.keys(col)
+	p.waitFor("Waiting for")
+}
+
+// update updates the player with the next message waiting, if there is one.
+func (p *player) update() bool {
+	select {
+	case msg := <-p.msgs:
+		var cmd tea.Cmd
+		p.m, cmd = p.m.Update(msg)
+		p.run(cmd)
+		return true
+	default:
+		return false
+	}
+}
+
+// waitFor updates both players until p's view contains s.
+func (p *player) waitFor(s string) {
+	p.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(p.m.View(), s) {
+		if time.Now().After(deadline) {
+			p.t.Fatalf("timed out waiting for %q in:\n%s", s, p.m.View())
+		}
+		if !p.update() && !p.other.update() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func connect(t *testing.T, id string) (host, guest *player) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	d, ok := game.Lookup(id)
+	if !ok {
+		t.Fatalf("no game %q", id)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host = newPlayer(t, Host(d, ln))
+
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	guest = newPlayer(t, Join(c))
+	host.other, guest.other = guest, host
+
+	return host, guest
+}
+
+func TestPlay(t *testing.T) {
+	host, guest := connect(t, "connect4")
+
+	// x, the host, gets four down before o.
+	for range 3 {
+		host.play("1")
+		guest.play("2")
+	}
+	host.play("2") // Not a win.
+	guest.play("3")
+	host.waitFor("Your turn")
+	host.keys("1")
+
+	host.waitFor("You won!")
+	guest.waitFor("You lost.")
+}
+
+func TestLeave(t *testing.T) {
+	host, guest := connect(t, "connect4")
+
+	host.waitFor("Your turn")
+	guest.waitFor("Waiting for x")
+	guest.keys("ctrl+c")
+
+	host.waitFor("the other player left")
+}
+
+func TestAddresses(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4321}
+	if got := addresses(addr); len(got) != 1 || got[0] != "127.0.0.1:4321" {
+		t.Errorf("addresses(%v) = %v, want just the address", addr, got)
+	}
+
+	all := addresses(&net.TCPAddr{IP: net.IPv4zero, Port: 4321})
+	if last := all[len(all)-1]; last != "localhost:4321" {
+		t.Errorf("addresses of every interface end with %q, want localhost:4321", last)
+	}
+}
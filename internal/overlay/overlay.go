@@ -0,0 +1,234 @@
+// Package overlay is the pause, help and quit screens games draw over
+// themselves.
+//
+// A game keeps a Model, passes it every message before handling the message
+// itself, and wraps its view with the model's View:
+//
+//	o, res := m.overlay.Update(msg)
+//	m.overlay = o
+//	switch res {
+//	case overlay.Quit:
+//		return m.gameOver(game.Quit)
+//	case overlay.Handled:
+//		return m, m.overlay.Sync(m.clock)
+//	}
+//
+// Opening the overlay pauses the game, and closing it carries on. Games with
+// a clock pass it to Sync whenever the overlay handled a message, which stops
+// the clock while the game is paused and starts it again after, in the same
+// Update that opened or closed the overlay.
+package overlay
+
+import (
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Keys returns the defaults for pausing, showing the help screen and
+// quitting with the keys quit, which games add to their own.
+func Keys(quit ...string) []keys.Default {
+	return []keys.Default{
+		{Action: keys.Pause, Keys: []string{"p", "esc"}, Help: "pause"},
+		{Action: keys.ShowHelp, Keys: []string{"?"}, Help: "help"},
+		{Action: keys.Quit, Keys: quit, Help: "quit"},
+	}
+}
+
+type screen int
+
+const (
+	closed screen = iota
+	paused
+	showingHelp
+	confirmingQuit
+)
+
+// Result tells the game what to do with a message once the overlay has
+// seen it.
+type Result int
+
+const (
+	Pass    Result = iota // The game should handle the message as usual.
+	Handled               // The overlay used the message; the game should ignore it.
+	Quit                  // The player confirmed they want to quit.
+)
+
+// Model is the overlay of a single game.
+type Model struct {
+	theme  theme.Theme
+	keys   keys.Map
+	rules  string
+	screen screen
+	back   screen // The screen to go back to if the player doesn't quit.
+}
+
+// New returns a closed overlay for a game with the bindings k, whose help
+// screen explains rules.
+func New(t theme.Theme, k keys.Map, rules string) Model {
+	return Model{theme: t, keys: k, rules: rules}
+}
+
+// Open reports whether the overlay is shown, and the game paused.
+func (o Model) Open() bool {
+	return o.screen != closed
+}
+
+// Update handles msg if the overlay is open, or if msg opens it.
+func (o Model) Update(msg tea.Msg) (Model, Result) {
+	if _, ok := msg.(game.SuspendMsg); ok {
+		if o.screen != closed {
+			return o, Handled
+		}
+		return o.show(paused), Handled
+	}
+
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		o.keys = o.keys.Fit(msg.Width)
+		return o, Pass
+	}
+
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return o, Pass
+	}
+
+	switch o.screen {
+	case closed:
+		switch {
+		case o.keys.Matches(k, keys.Quit):
+			return o.show(confirmingQuit), Handled
+		case o.keys.Matches(k, keys.Pause):
+			return o.show(paused), Handled
+		case o.keys.Matches(k, keys.ShowHelp):
+			return o.show(showingHelp), Handled
+		}
+		return o, Pass
+
+	case confirmingQuit:
+		switch k.String() {
+		case "y", "Y", "enter":
+			o.screen = closed
+			return o, Quit
+		case "n", "N", "esc":
+			return o.close(o.back)
+		}
+		if o.keys.Matches(k, keys.Quit) {
+			o.screen = closed
+			return o, Quit
+		}
+
+	case paused:
+		switch {
+		case o.keys.Matches(k, keys.Quit):
+			return o.show(confirmingQuit), Handled
+		case o.keys.Matches(k, keys.ShowHelp):
+			return o.show(showingHelp), Handled
+		case o.keys.Matches(k, keys.Pause), k.String() == "enter":
+			// Enter works too, for games that can't be paused by the
+			// player but were suspended.
+			return o.close(closed)
+		}
+
+	case showingHelp:
+		switch {
+		case o.keys.Matches(k, keys.Quit):
+			return o.show(confirmingQuit), Handled
+		case o.keys.Matches(k, keys.ShowHelp), o.keys.Matches(k, keys.Pause), k.String() == "esc":
+			return o.close(o.back)
+		}
+	}
+
+	// Other keys are swallowed, so the game doesn't move while it is paused.
+	return o, Handled
+}
+
+// show switches to s, remembering which screen to go back to.
+func (o Model) show(s screen) Model {
+	if o.screen == closed || o.screen == paused {
+		o.back = o.screen
+	}
+	o.screen = s
+	return o
+}
+
+// close goes back to s, carrying on with the game if s is closed.
+func (o Model) close(s screen) (Model, Result) {
+	o.screen = s
+	o.back = closed
+	return o, Handled
+}
+
+// Sync stops c while the overlay is open, and starts it again once the
+// overlay is closed. It returns the command that sends the next tick, if c
+// was started.
+func (o Model) Sync(c game.Clock) tea.Cmd {
+	if o.Open() {
+		c.Stop()
+		return nil
+	}
+	return c.Start()
+}
+
+// Help returns the help footer listing the game's keys, cut to fit in the
+// terminal.
+func (o Model) Help() string {
+	return o.keys.Help()
+}
+
+// View returns view, the game's view, or the overlay in its place if it is
+// open. The overlay takes up the same space, so the screen doesn't jump.
+func (o Model) View(view string) string {
+	var lines []string
+	hint := lipgloss.NewStyle().Faint(true)
+
+	switch o.screen {
+	case closed:
+		return view
+
+	case paused:
+		lines = append(lines, o.theme.Highlight.Render("Paused"), "")
+		resume := "enter"
+		if l := o.keys.Label(keys.Pause); l != "" {
+			resume = l
+		}
+		lines = append(lines, hint.Render(resume+": carry on"))
+		if l := o.keys.Label(keys.ShowHelp); l != "" {
+			lines = append(lines, hint.Render(l+": help"))
+		}
+		if l := o.keys.Label(keys.Quit); l != "" {
+			lines = append(lines, hint.Render(l+": quit"))
+		}
+
+	case showingHelp:
+		lines = append(lines,
+			o.theme.Highlight.Render("How to play"),
+			"",
+			lipgloss.NewStyle().Width(40).Render(o.rules),
+			"",
+			o.keys.FullHelp(),
+			"",
+			hint.Render("esc: back"),
+		)
+
+	case confirmingQuit:
+		lines = append(lines,
+			o.theme.Highlight.Render("Quit this game?"),
+			"",
+			hint.Render("y: quit  n: carry on"),
+		)
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(o.theme.Wall.GetForeground()).
+		Padding(0, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.Place(lipgloss.Width(view), lipgloss.Height(view), lipgloss.Center, lipgloss.Center, box)
+}
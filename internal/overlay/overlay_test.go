@@ -0,0 +1,132 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/keys"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(k string) tea.KeyMsg {
+	if k == "esc" {
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func newOverlay() Model {
+	k := keys.Config{}.For("test", append(keys.Arrows(), Keys("q")...)...)
+	t, _ := theme.Get("monochrome")
+	return New(t, k, "Test the overlay.")
+}
+
+// running syncs c with o, the way a game does after the overlay handled a
+// message, and reports whether c is running.
+func running(o Model, c game.Clock) bool {
+	o.Sync(c)
+	return c.Next() != nil
+}
+
+func TestPause(t *testing.T) {
+	o := newOverlay()
+	c := game.NewClock("test", time.Hour)
+
+	o, res := o.Update(press("p"))
+	if res != Handled || !o.Open() || running(o, c) {
+		t.Fatalf("p didn't pause the game: %v, open %v", res, o.Open())
+	}
+
+	// Game keys are swallowed while paused.
+	if _, res := o.Update(press("k")); res != Handled {
+		t.Errorf("k while paused gave %v, want Handled", res)
+	}
+
+	o, res = o.Update(press("esc"))
+	if res != Handled || o.Open() || !running(o, c) {
+		t.Fatalf("esc didn't carry on with the game: %v, open %v", res, o.Open())
+	}
+
+	if _, res := o.Update(press("k")); res != Pass {
+		t.Errorf("k while playing gave %v, want Pass", res)
+	}
+}
+
+func TestPauseTwice(t *testing.T) {
+	o := newOverlay()
+	c := game.NewClock("test", time.Hour)
+
+	// Pausing and carrying on straight away leaves the clock running, as
+	// each press stops or starts it before the next one is handled.
+	for _, k := range []string{"p", "p"} {
+		o, _ = o.Update(press(k))
+		o.Sync(c)
+	}
+	if o.Open() || c.Next() == nil {
+		t.Errorf("pausing twice left the overlay open %v, or the clock stopped", o.Open())
+	}
+}
+
+func TestQuit(t *testing.T) {
+	o := newOverlay()
+
+	c := game.NewClock("test", time.Hour)
+
+	o, res := o.Update(press("q"))
+	if res != Handled || !o.Open() || running(o, c) {
+		t.Fatalf("q quit straight away, or didn't pause: %v", res)
+	}
+
+	o, res = o.Update(press("n"))
+	if res != Handled || o.Open() || !running(o, c) {
+		t.Fatalf("n didn't carry on with the game: %v, open %v", res, o.Open())
+	}
+
+	o, _ = o.Update(press("q"))
+	if _, res := o.Update(press("y")); res != Quit {
+		t.Errorf("y gave %v, want Quit", res)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	o := newOverlay()
+
+	// Help opened from the pause screen goes back to it.
+	o, _ = o.Update(press("p"))
+	o, _ = o.Update(press("?"))
+
+	view := o.View(strings.Repeat(strings.Repeat(".", 60)+"\n", 20))
+	if !strings.Contains(view, "Test the overlay.") || !strings.Contains(view, "quit") {
+		t.Errorf("help screen doesn't show the rules and keys:\n%s", view)
+	}
+
+	o, _ = o.Update(press("?"))
+	if !o.Open() {
+		t.Fatal("closing help didn't go back to the pause screen")
+	}
+	if view := o.View("game"); !strings.Contains(view, "Paused") {
+		t.Errorf("pause screen doesn't say so:\n%s", view)
+	}
+}
+
+func TestSuspend(t *testing.T) {
+	o := newOverlay()
+
+	o, res := o.Update(game.SuspendMsg{})
+	if res != Handled || !o.Open() || running(o, game.NewClock("test", time.Hour)) {
+		t.Fatalf("suspending didn't pause the game: %v, open %v", res, o.Open())
+	}
+
+	// Suspending again changes nothing; the player carries on when ready.
+	o, _ = o.Update(game.SuspendMsg{})
+	if !o.Open() {
+		t.Error("suspending a paused game changed it")
+	}
+	if view := o.View("game"); !strings.Contains(view, "Paused") {
+		t.Errorf("suspended game doesn't show the pause screen:\n%s", view)
+	}
+}
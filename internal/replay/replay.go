@@ -0,0 +1,154 @@
+// Package replay records the messages a game receives, so the game can be
+// played back exactly.
+//
+// Games only change in response to messages and draw every random number
+// from their seeded source, so the seed, the options the game was started
+// with and the keys and ticks it received are enough to rebuild every frame.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// format is the version of the file layout below.
+const format = 1
+
+// File is a replay as stored on disk.
+type File struct {
+	Format   int       `json:"format"`
+	Game     string    `json:"game"`
+	Args     []string  `json:"args,omitempty"` // The command line flags the game was started with.
+	Seed     uint64    `json:"seed"`
+	Resume   *Save     `json:"resume,omitempty"` // Set if the game was resumed from a save.
+	Recorded time.Time `json:"recorded"`
+	Events   []Event   `json:"events"`
+}
+
+// Save is the save a resumed game started from.
+type Save struct {
+	Version int             `json:"version"`
+	State   json.RawMessage `json:"state"`
+}
+
+// Event is a message the game received. Exactly one of Key, Tick, Size and
+// Suspend is set.
+type Event struct {
+	At      time.Duration `json:"at"` // Since the game started.
+	Key     *Key          `json:"key,omitempty"`
+	Tick    string        `json:"tick,omitempty"` // The name of a clock in the game's Descriptor.Ticks.
+	Size    *[2]int       `json:"size,omitempty"`
+	Suspend bool          `json:"suspend,omitempty"` // A game.SuspendMsg.
+}
+
+// Key is a tea.KeyMsg.
+type Key struct {
+	Type  tea.KeyType `json:"type"`
+	Runes string      `json:"runes,omitempty"`
+	Alt   bool        `json:"alt,omitempty"`
+}
+
+// New returns an empty replay of game d, started with args from seed.
+func New(d game.Descriptor, args []string, seed uint64) *File {
+	return &File{
+		Format:   format,
+		Game:     d.ID,
+		Args:     args,
+		Seed:     seed,
+		Recorded: time.Now(),
+	}
+}
+
+// Add records msg if it is one of the messages replays keep, and reports
+// whether it was.
+func (f *File) Add(d game.Descriptor, at time.Duration, msg tea.Msg) bool {
+	e := Event{At: at}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		e.Key = &Key{Type: msg.Type, Runes: string(msg.Runes), Alt: msg.Alt}
+	case tea.WindowSizeMsg:
+		e.Size = &[2]int{msg.Width, msg.Height}
+	case game.SuspendMsg:
+		e.Suspend = true
+	case game.TickMsg:
+		if !slices.Contains(d.Ticks, msg.Name) {
+			return false
+		}
+		e.Tick = msg.Name
+	default:
+		return false
+	}
+
+	f.Events = append(f.Events, e)
+	return true
+}
+
+// Msg returns the message e records, for the game d.
+func (e Event) Msg(d game.Descriptor) (tea.Msg, error) {
+	switch {
+	case e.Key != nil:
+		msg := tea.KeyMsg{Type: e.Key.Type, Alt: e.Key.Alt}
+		if e.Key.Runes != "" {
+			msg.Runes = []rune(e.Key.Runes)
+		}
+		return msg, nil
+	case e.Size != nil:
+		return tea.WindowSizeMsg{Width: e.Size[0], Height: e.Size[1]}, nil
+	case e.Suspend:
+		return game.SuspendMsg{}, nil
+	}
+
+	if !slices.Contains(d.Ticks, e.Tick) {
+		return nil, fmt.Errorf("%s has no tick called %q", d.ID, e.Tick)
+	}
+	return game.TickMsg{Name: e.Tick}, nil
+}
+
+// Last returns the path the last game of game is recorded to.
+func Last(game string) (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "replays", game+".json"), nil
+}
+
+// Write stores f at path.
+func Write(path string, f *File) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFile(path, data)
+}
+
+// Read loads the replay at path.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	if f.Format != format {
+		return nil, fmt.Errorf("unsupported replay format %d", f.Format)
+	}
+
+	return &f, nil
+}
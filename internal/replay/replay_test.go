@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRoundTrip(t *testing.T) {
+	d := game.Descriptor{ID: "test", Ticks: []string{"tick"}}
+
+	msgs := []tea.Msg{
+		tea.WindowSizeMsg{Width: 80, Height: 24},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")},
+		game.TickMsg{Name: "tick"},
+		game.SuspendMsg{},
+		tea.KeyMsg{Type: tea.KeyUp, Alt: true},
+	}
+
+	f := New(d, []string{"--width", "30"}, 42)
+	for i, msg := range msgs {
+		if !f.Add(d, time.Duration(i)*time.Second, msg) {
+			t.Fatalf("expected %T to be recorded", msg)
+		}
+	}
+	if f.Add(d, 0, game.OverMsg{}) {
+		t.Error("expected messages that aren't input or ticks to be skipped")
+	}
+	if f.Add(d, 0, game.TickMsg{Name: "other"}) {
+		t.Error("expected ticks the game doesn't list to be skipped")
+	}
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := Write(path, f); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Seed != 42 || !reflect.DeepEqual(got.Args, f.Args) {
+		t.Errorf("expected seed 42 and args %v, got %d and %v", f.Args, got.Seed, got.Args)
+	}
+	if len(got.Events) != len(msgs) {
+		t.Fatalf("expected %d events, got %d", len(msgs), len(got.Events))
+	}
+
+	for i, e := range got.Events {
+		msg, err := e.Msg(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(msg, msgs[i]) {
+			t.Errorf("event %d: expected %#v, got %#v", i, msgs[i], msg)
+		}
+	}
+}
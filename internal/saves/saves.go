@@ -0,0 +1,162 @@
+// Package saves stores games that were left part way through, one file per
+// save. Every game that is quit gets a save of its own, so starting another
+// game of the same title never replaces or removes it.
+package saves
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+// format is the version of the file layout below, as opposed to the
+// version of a game's state inside it.
+const format = 1
+
+// File is a save as stored on disk.
+type File struct {
+	ID      string          `json:"-"` // The name of the file, without .json.
+	Format  int             `json:"format"`
+	Game    string          `json:"game"`
+	Version int             `json:"version"`
+	Saved   time.Time       `json:"saved"`
+	Played  time.Duration   `json:"played,omitempty"` // How long the game had been played for.
+	State   json.RawMessage `json:"state"`
+}
+
+func dir() (string, error) {
+	data, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(data, "saves"), nil
+}
+
+func path(id string) (string, error) {
+	d, err := dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(d, id+".json"), nil
+}
+
+// NewID returns the ID of a new save of game, which no other save has.
+func NewID(game string) string {
+	return game + "-" + strconv.FormatUint(rand.Uint64(), 36)
+}
+
+// Write saves the state of game, played for played so far, as the save id,
+// replacing the save with that ID if there is one.
+func Write(id, game string, version int, played time.Duration, state any) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(File{
+		Format:  format,
+		Game:    game,
+		Version: version,
+		Saved:   time.Now(),
+		Played:  played,
+		State:   raw,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	p, err := path(id)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFile(p, data)
+}
+
+// Read returns the save id.
+func Read(id string) (File, error) {
+	p, err := path(id)
+	if err != nil {
+		return File{}, err
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return File{}, err
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return File{}, err
+	}
+
+	if f.Format != format {
+		return File{}, fmt.Errorf("unknown save file format %d", f.Format)
+	}
+
+	f.ID = id
+	return f, nil
+}
+
+// Remove deletes the save id, if there is one.
+func Remove(id string) error {
+	p, err := path(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// List returns every save, most recent first. Saves that can't be read are
+// left out.
+func List() ([]File, error) {
+	d, err := dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(d)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+
+		f, err := Read(id)
+		if err != nil {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	slices.SortFunc(files, func(a, b File) int {
+		return b.Saved.Compare(a.Saved)
+	})
+
+	return files, nil
+}
@@ -0,0 +1,82 @@
+package saves
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+type state struct {
+	Grid  [2][2]int `json:"grid"`
+	Score int       `json:"score"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	want := state{Grid: [2][2]int{{2, 4}, {0, 8}}, Score: 12}
+	id := NewID("twenty48")
+	if err := Write(id, "twenty48", 1, 3*time.Minute, want); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Read(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Played != 3*time.Minute {
+		t.Errorf("expected the game to have been played for 3m, got %v", f.Played)
+	}
+
+	var got state
+	if err := (game.Save{Version: f.Version, State: f.State}).Decode(1, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	// A save from a newer format can't be read by an older gg.
+	if err := (game.Save{Version: 2, State: f.State}).Decode(1, &got); err == nil {
+		t.Error("expected an error decoding a newer version")
+	}
+
+	files, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Game != "twenty48" || files[0].ID != id {
+		t.Errorf("expected one save for twenty48, got %+v", files)
+	}
+
+	if err := Remove(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(id); err != nil {
+		t.Errorf("removing a missing save: %v", err)
+	}
+	if files, _ := List(); len(files) != 0 {
+		t.Errorf("expected no saves, got %d", len(files))
+	}
+}
+
+func TestTwoSaves(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	a, b := NewID("twenty48"), NewID("twenty48")
+	if a == b {
+		t.Fatalf("two saves of twenty48 both got the ID %q", a)
+	}
+	Write(a, "twenty48", 1, 0, state{Score: 1})
+	Write(b, "twenty48", 1, 0, state{Score: 2})
+
+	if files, _ := List(); len(files) != 2 {
+		t.Errorf("expected two saves, got %d", len(files))
+	}
+	Remove(a)
+	if files, _ := List(); len(files) != 1 || files[0].ID != b {
+		t.Errorf("removing %s left %+v", a, files)
+	}
+}
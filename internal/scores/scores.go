@@ -0,0 +1,176 @@
+// Package scores keeps the best scores of every game on disk.
+//
+// Games call Record when a run ends and Best to show the score to beat. A
+// failure to read or write the file is never worth interrupting a game for,
+// so games are free to ignore the errors these return.
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+// Kept is the amount of scores kept for each game.
+const Kept = 10
+
+type Entry struct {
+	Score int       `json:"score"`
+	Time  time.Time `json:"time"`
+}
+
+// Store is a score table saved as JSON. It is safe to use from several
+// processes at once.
+type Store struct {
+	path string
+}
+
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Default opens the store in gg's data directory.
+func Default() (*Store, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return Open(filepath.Join(dir, "scores.json")), nil
+}
+
+func (s *Store) load() (map[string][]Entry, error) {
+	tables := map[string][]Entry{}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tables, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// Record adds a score for game and returns the best score for it, which
+// may be the new one.
+func (s *Store) Record(game string, score int) (int, error) {
+	unlock, err := storage.Lock(s.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	tables, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+
+	table := append(tables[game], Entry{Score: score, Time: time.Now()})
+	// A stable sort keeps older entries ahead of newer ones on a tie.
+	slices.SortStableFunc(table, func(a, b Entry) int {
+		return b.Score - a.Score
+	})
+	if len(table) > Kept {
+		table = table[:Kept]
+	}
+	tables[game] = table
+
+	data, err := json.MarshalIndent(tables, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := storage.WriteFile(s.path, data); err != nil {
+		return 0, err
+	}
+
+	return table[0].Score, nil
+}
+
+// Top returns the best scores for game, best first.
+func (s *Store) Top(game string) ([]Entry, error) {
+	tables, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return tables[game], nil
+}
+
+// Games returns the names of every game with a score, sorted.
+func (s *Store) Games() ([]string, error) {
+	tables, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	games := make([]string, 0, len(tables))
+	for game := range tables {
+		games = append(games, game)
+	}
+	slices.Sort(games)
+
+	return games, nil
+}
+
+// Best returns the best score for game, or 0 if there is none.
+func (s *Store) Best(game string) (int, error) {
+	top, err := s.Top(game)
+	if err != nil || len(top) == 0 {
+		return 0, err
+	}
+
+	return top[0].Score, nil
+}
+
+// readOnly stops Record from adding scores to the default store.
+var readOnly bool
+
+// SetReadOnly controls whether Record keeps scores. It is turned on while
+// watching a replay, so the same run isn't counted twice.
+func SetReadOnly(b bool) {
+	readOnly = b
+}
+
+// ReadOnly reports whether Record keeps scores. Games that keep other
+// records should follow it.
+func ReadOnly() bool {
+	return readOnly
+}
+
+// Record adds a score to the default store. See Store.Record.
+func Record(game string, score int) (int, error) {
+	s, err := Default()
+	if err != nil {
+		return 0, err
+	}
+
+	if readOnly {
+		best, err := s.Best(game)
+		return max(best, score), err
+	}
+
+	return s.Record(game, score)
+}
+
+// Best returns the best score in the default store. See Store.Best.
+func Best(game string) (int, error) {
+	s, err := Default()
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Best(game)
+}
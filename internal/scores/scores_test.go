@@ -0,0 +1,67 @@
+package scores
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	s := Open(filepath.Join(t.TempDir(), "scores.json"))
+
+	for i := 1; i <= 15; i++ {
+		if _, err := s.Record("snake", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	best, err := s.Record("snake", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best != 15 {
+		t.Errorf("expected best 15, got %d", best)
+	}
+
+	top, err := s.Top("snake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != Kept {
+		t.Fatalf("expected %d scores, got %d", Kept, len(top))
+	}
+	for i, e := range top {
+		if e.Score != 15-i {
+			t.Errorf("#%d: expected score %d, got %d", i, 15-i, e.Score)
+		}
+	}
+
+	if best, _ := s.Best("pong"); best != 0 {
+		t.Errorf("expected no best score for pong, got %d", best)
+	}
+}
+
+func TestRecordConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each goroutine opens its own store, like separate processes would.
+			if _, err := Open(path).Record("dodger", i); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	top, err := Open(path).Top("dodger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 8 {
+		t.Errorf("expected 8 scores, got %d", len(top))
+	}
+}
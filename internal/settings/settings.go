@@ -0,0 +1,41 @@
+// Package settings reads the player's preferences from config.json in gg's
+// config directory.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+// Settings are the player's preferences. The zero value means every default.
+type Settings struct {
+	Theme string `json:"theme,omitempty"`
+}
+
+// Read reads the settings. A missing file gives the defaults.
+func Read() (Settings, error) {
+	dir, err := storage.ConfigDir()
+	if err != nil {
+		return Settings{}, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Settings{}, nil
+	}
+	if err != nil {
+		return Settings{}, err
+	}
+
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Settings{}, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,112 @@
+// Package sshserver serves gg over SSH, so people can play without
+// installing it: every connection gets a menu and games of its own.
+package sshserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/crash"
+	"github.com/Kaamkiya/gg/internal/host"
+	"github.com/Kaamkiya/gg/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
+)
+
+// DefaultPort is the port gg ssh listens on unless told otherwise.
+const DefaultPort = 2222
+
+// idleTimeout is how long a connection may go without input before it is
+// closed, so forgotten sessions don't pile up.
+const idleTimeout = 30 * time.Minute
+
+// HostKeyPath returns where the server's host key is kept. It is generated
+// the first time the server starts.
+func HostKeyPath() (string, error) {
+	dir, err := storage.SharedDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ssh_host_ed25519"), nil
+}
+
+// New returns a server that listens on addr. Every player plays in the
+// profile the server was started with, and so shares its scores.
+func New(addr string) (*ssh.Server, error) {
+	keyPath, err := HostKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0o755); err != nil {
+		return nil, err
+	}
+
+	// The themes draw with lipgloss's default renderer, which can't tell
+	// what each client's terminal supports. Nearly every terminal that
+	// connects over SSH has 256 colours and a dark background.
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	lipgloss.SetHasDarkBackground(true)
+
+	return wish.NewServer(
+		wish.WithAddress(addr),
+		wish.WithHostKeyPath(keyPath),
+		wish.WithIdleTimeout(idleTimeout),
+		wish.WithMiddleware(
+			bm.Middleware(handler),
+			activeterm.Middleware(),
+			logging.MiddlewareWithLogger(log.Default()),
+		),
+	)
+}
+
+// handler starts the menu for a new connection.
+func handler(ssh.Session) (tea.Model, []tea.ProgramOption) {
+	return crash.Catch(host.NewShared()), []tea.ProgramOption{tea.WithAltScreen()}
+}
+
+// ListenAndServe runs s until the process is interrupted, then gives the
+// players connected a few seconds to be disconnected cleanly.
+func ListenAndServe(s *ssh.Server) error {
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("serving gg on ssh://%s", ln.Addr())
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-done:
+	}
+
+	log.Print("stopping")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package sshserver
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+
+	_ "github.com/Kaamkiya/gg/internal/app/snake"
+)
+
+// serve starts a server on a free port and returns its address.
+func serve(t *testing.T) string {
+	t.Helper()
+
+	s, err := New("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(ln)
+	t.Cleanup(func() { s.Close() })
+
+	return ln.Addr().String()
+}
+
+// screen collects what a session draws.
+type screen struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *screen) contains(text string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return strings.Contains(s.buf.String(), text)
+}
+
+// connect opens a session with a terminal and returns what it draws.
+func connect(t *testing.T, addr string) *screen {
+	t.Helper()
+
+	client, err := gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User:            "player",
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	sess, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &screen{}
+	sess.Stdout = out
+	if err := sess.RequestPty("xterm-256color", 40, 100, gossh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.Shell(); err != nil {
+		t.Fatal(err)
+	}
+
+	return out
+}
+
+func waitFor(t *testing.T, s *screen, text string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !s.contains(text) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q", text)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMenu(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	addr := serve(t)
+
+	// Everyone gets a menu of their own.
+	first := connect(t, addr)
+	second := connect(t, addr)
+	waitFor(t, first, "snake")
+	waitFor(t, second, "snake")
+}
+
+func TestHostKey(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	serve(t)
+	path, err := HostKeyPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("no host key was generated: %v", err)
+	}
+
+	// Later runs keep the same key, or clients would warn of an attack.
+	serve(t)
+	again, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, again) {
+		t.Error("the host key changed between runs")
+	}
+}
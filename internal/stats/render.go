@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Render draws the statistics of sessions in width columns: totals, a chart
+// of the time spent on each game and of how often each was played, then the
+// details of each game.
+func Render(sessions []Session, width int, t theme.Theme) string {
+	if len(sessions) == 0 {
+		return "No games played yet. Go play something!"
+	}
+
+	summaries := Summarize(sessions)
+
+	var total time.Duration
+	played := 0
+	for _, s := range summaries {
+		total += s.Time
+		played += s.Played
+	}
+
+	title := lipgloss.NewStyle().Bold(true)
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s, %s of play since %s\n\n", plural(played, "game"), duration(total), sessions[0].Start.Format("Jan 2 2006"))
+
+	b.WriteString(title.Render("Time played") + "\n")
+	b.WriteString(chart(summaries, width, t, func(s Summary) (int, string) {
+		return int(s.Time / time.Second), duration(s.Time)
+	}))
+
+	b.WriteString("\n" + title.Render("Games played") + "\n")
+	b.WriteString(chart(summaries, width, t, func(s Summary) (int, string) {
+		return s.Played, strconv.Itoa(s.Played)
+	}))
+
+	for _, s := range summaries {
+		b.WriteString("\n" + title.Render(name(s.Game)) + "\n")
+		b.WriteString(details(s))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// details describes how a game went, in a line or two.
+func details(s Summary) string {
+	line := fmt.Sprintf("  %s in %s, %s: %s\n", plural(s.Played, "game"), duration(s.Time), plural(s.Keys, "key"), rates(s.Outcomes, s.Rate))
+
+	if len(s.Matches) > 0 {
+		line += "  matches: " + rates(s.Matches, s.MatchRate) + "\n"
+	}
+	if avg := s.AverageWin(); avg > 0 {
+		line += fmt.Sprintf("  average time to win: %s\n", duration(avg))
+	}
+	if d, ok := game.Lookup(s.Game); ok && d.Score != "" {
+		line += fmt.Sprintf("  best %s: %d\n", d.Score, s.Best)
+	}
+
+	return line
+}
+
+// rates lists how many times each outcome in counts happened, and how
+// often.
+func rates(counts map[string]int, rate func(string) float64) string {
+	var parts []string
+	for _, o := range outcomes {
+		if n := counts[o]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d (%.0f%%)", past(o), n, rate(o)*100))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// past turns an outcome into what the player did, e.g. "draw" into "drawn".
+func past(outcome string) string {
+	if outcome == game.Draw.String() {
+		return "drawn"
+	}
+	return outcome
+}
+
+// chart draws a bar for each game, as long as its share of the biggest
+// value. value returns the size of a game's bar and how to label it.
+func chart(summaries []Summary, width int, t theme.Theme, value func(Summary) (int, string)) string {
+	labelWidth, valueWidth, biggest := 0, 0, 0
+	for _, s := range summaries {
+		n, label := value(s)
+		labelWidth = max(labelWidth, lipgloss.Width(name(s.Game)))
+		valueWidth = max(valueWidth, len(label))
+		biggest = max(biggest, n)
+	}
+
+	barWidth := min(max(width-labelWidth-valueWidth-4, 10), 50)
+
+	var b strings.Builder
+	for _, s := range summaries {
+		n, label := value(s)
+		bar := 0
+		if biggest > 0 {
+			bar = n * barWidth / biggest
+		}
+		if n > 0 {
+			bar = max(bar, 1)
+		}
+
+		fmt.Fprintf(&b, "%-*s  %s%s %s\n",
+			labelWidth, name(s.Game),
+			t.Highlight.Render(strings.Repeat("█", bar)),
+			strings.Repeat(" ", barWidth-bar),
+			label)
+	}
+
+	return b.String()
+}
+
+// name returns the name of the game with the given ID.
+func name(id string) string {
+	if d, ok := game.Lookup(id); ok {
+		return d.Label()
+	}
+	return id
+}
+
+// duration formats d to the minute, or to the second if it is shorter.
+func duration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+
+	s := d.Round(time.Minute).String()
+	return strings.TrimSuffix(s, "0s")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
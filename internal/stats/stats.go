@@ -0,0 +1,141 @@
+// Package stats keeps a log of every game played, and sums it up for
+// gg stats and the statistics screen.
+//
+// The log is a JSON file in the profile's data directory with one session
+// per line. It never leaves the machine.
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+// Session is a single game, from when it started until the player left it.
+type Session struct {
+	Game    string    `json:"game"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Outcome string    `json:"outcome"` // As game.Outcome.String() has it.
+	Score   int       `json:"score"`
+	Moves   int       `json:"moves,omitempty"`
+	Keys    int       `json:"keys"` // How many keys were pressed while playing.
+	Daily   bool      `json:"daily,omitempty"`
+	Matches []string  `json:"matches,omitempty"` // How each match went, like Outcome.
+
+	// Saved is set if the player quit and the game was saved, so it isn't
+	// over yet. Its outcome is left to the session that resumes it.
+	Saved bool `json:"saved,omitempty"`
+	// Earlier is how long the game was played, in the sessions before the
+	// one it was resumed in.
+	Earlier time.Duration `json:"earlier,omitempty"`
+}
+
+// New returns the session of a game of id that started at start and ended
+// now with r.
+func New(id string, start time.Time, r game.Result) Session {
+	s := Session{
+		Game:    id,
+		Start:   start,
+		End:     time.Now(),
+		Outcome: r.Outcome.String(),
+		Score:   r.Score,
+		Moves:   r.Moves,
+	}
+	for _, o := range r.Matches {
+		s.Matches = append(s.Matches, o.String())
+	}
+	return s
+}
+
+// Duration returns how long the session lasted.
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// GameDuration returns how long the game took, including the sessions
+// before it was resumed.
+func (s Session) GameDuration() time.Duration {
+	return s.Earlier + s.Duration()
+}
+
+func path() (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "sessions.jsonl"), nil
+}
+
+// Log adds s to the log of the current profile. Watching a replay doesn't
+// count as playing, so nothing is logged while scores are read only.
+func Log(s Session) error {
+	if scores.ReadOnly() {
+		return nil
+	}
+
+	p, err := path()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := storage.Lock(p)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load returns every session in the log of the current profile, oldest
+// first. Lines that can't be read, e.g. one cut short by a crash, are
+// skipped.
+func Load() ([]Session, error) {
+	p, err := path()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sessions []Session
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Session
+		if err := json.Unmarshal(scanner.Bytes(), &s); err == nil {
+			sessions = append(sessions, s)
+		}
+	}
+
+	return sessions, scanner.Err()
+}
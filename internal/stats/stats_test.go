@@ -0,0 +1,147 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var start = time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+
+// session returns a session of id that lasted d and ended with outcome.
+func session(id string, d time.Duration, outcome game.Outcome, score int) Session {
+	return Session{Game: id, Start: start, End: start.Add(d), Outcome: outcome.String(), Score: score}
+}
+
+func TestLog(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	if sessions, err := Load(); err != nil || len(sessions) != 0 {
+		t.Fatalf("Load() = %v, %v before anything was logged", sessions, err)
+	}
+
+	first := New("snake", start, game.Result{Outcome: game.Lost, Score: 12})
+	if err := Log(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line cut short by a crash is skipped.
+	f, err := os.OpenFile(filepath.Join(dir, "gg", "sessions.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"game": "sn` + "\n")
+	f.Close()
+
+	second := New("tictactoe-ai", start, game.Result{Outcome: game.Quit, Matches: []game.Outcome{game.Won, game.Draw}})
+	if err := Log(second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Watching a replay isn't playing.
+	scores.SetReadOnly(true)
+	Log(first)
+	scores.SetReadOnly(false)
+
+	sessions, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	if s := sessions[0]; s.Game != "snake" || s.Outcome != "lost" || s.Score != 12 {
+		t.Errorf("first session is %+v", s)
+	}
+	if s := sessions[1]; strings.Join(s.Matches, ",") != "won,draw" {
+		t.Errorf("second session has the matches %v, want won,draw", s.Matches)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	sessions := []Session{
+		session("sudoku", 10*time.Minute, game.Won, 0),
+		session("snake", time.Minute, game.Lost, 4),
+		session("sudoku", 20*time.Minute, game.Won, 0),
+		session("snake", 2*time.Minute, game.Lost, 9),
+		session("sudoku", time.Hour, game.Quit, 0),
+	}
+
+	summaries := Summarize(sessions)
+	if len(summaries) != 2 || summaries[0].Game != "sudoku" || summaries[1].Game != "snake" {
+		t.Fatalf("got %+v, want sudoku then snake", summaries)
+	}
+
+	sudoku := summaries[0]
+	if sudoku.Played != 3 || sudoku.Time != 90*time.Minute {
+		t.Errorf("sudoku was played %d times for %v, want 3 for 1h30m", sudoku.Played, sudoku.Time)
+	}
+	if avg := sudoku.AverageWin(); avg != 15*time.Minute {
+		t.Errorf("sudoku takes %v to win, want 15m", avg)
+	}
+	if r := sudoku.Rate("won"); r < 0.66 || r > 0.67 {
+		t.Errorf("sudoku is won %.2f of the time, want 2/3", r)
+	}
+
+	snake := summaries[1]
+	if snake.Best != 9 || snake.AverageWin() != 0 {
+		t.Errorf("snake has a best of %d and wins in %v, want 9 and 0", snake.Best, snake.AverageWin())
+	}
+}
+
+func TestSummarize_Saved(t *testing.T) {
+	// A game saved after 10 minutes, then resumed and won 5 minutes later.
+	saved := session("sudoku", 10*time.Minute, game.Quit, 0)
+	saved.Saved, saved.Keys = true, 40
+	resumed := session("sudoku", 5*time.Minute, game.Won, 0)
+	resumed.Earlier, resumed.Keys = 10*time.Minute, 20
+
+	sum := Summarize([]Session{saved, resumed})[0]
+	if sum.Played != 1 || sum.Rate("won") != 1 || sum.Outcomes["quit"] != 0 {
+		t.Errorf("the saved game counts as %d games with the outcomes %v, want one win", sum.Played, sum.Outcomes)
+	}
+	if sum.Time != 15*time.Minute || sum.Keys != 60 {
+		t.Errorf("sudoku was played for %v with %d keys, want 15m and 60", sum.Time, sum.Keys)
+	}
+	if avg := sum.AverageWin(); avg != 15*time.Minute {
+		t.Errorf("sudoku takes %v to win, want the whole 15m", avg)
+	}
+}
+
+func TestRender(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	if s := Render(nil, 80, theme.Current()); !strings.Contains(s, "No games") {
+		t.Errorf("with no sessions got %q", s)
+	}
+
+	ai := session("tictactoe-ai", 5*time.Minute, game.Quit, 3)
+	ai.Matches = []string{"won", "won", "won", "lost"}
+	sessions := []Session{
+		session("snake", 10*time.Minute, game.Lost, 4),
+		session("snake", 20*time.Minute, game.Lost, 9),
+		ai,
+	}
+
+	s := Render(sessions, 80, theme.Current())
+	for _, want := range []string{
+		"3 games, 35m of play since Jan 2 2026",
+		strings.Repeat("█", 50) + " 30m",
+		"lost 2 (100%)",
+		"matches: won 3 (75%), lost 1 (25%)",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q in:\n%s", want, s)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// outcomes lists how a session or match can end, in the order they are
+// shown.
+var outcomes = []string{game.Won.String(), game.Lost.String(), game.Draw.String(), game.Quit.String()}
+
+// Summary sums up the sessions of one game. Sessions that ended with the
+// game saved count towards the time and keys, but not towards the games
+// played or how they ended, which the session that resumed them has.
+type Summary struct {
+	Game   string
+	Played int           // How many games were played to the end or quit.
+	Time   time.Duration // How long the game was played for, altogether.
+	Keys   int
+
+	Outcomes map[string]int // How many sessions ended each way.
+	Matches  map[string]int // How many matches ended each way, for games of several.
+	WonTime  time.Duration  // How long the sessions that were won took, altogether.
+
+	Best int // The best score, for games with one worth comparing.
+}
+
+// Rate returns the share of sessions that ended with outcome, from 0 to 1.
+func (s Summary) Rate(outcome string) float64 {
+	return rate(s.Outcomes, outcome)
+}
+
+// MatchRate returns the share of matches that ended with outcome, from 0 to
+// 1.
+func (s Summary) MatchRate(outcome string) float64 {
+	return rate(s.Matches, outcome)
+}
+
+func rate(counts map[string]int, outcome string) float64 {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	return float64(counts[outcome]) / float64(total)
+}
+
+// AverageWin returns how long a win takes on average, or 0 if there were
+// none.
+func (s Summary) AverageWin() time.Duration {
+	won := s.Outcomes[game.Won.String()]
+	if won == 0 {
+		return 0
+	}
+
+	return s.WonTime / time.Duration(won)
+}
+
+// Summarize sums up sessions by game, the game played longest first.
+func Summarize(sessions []Session) []Summary {
+	byGame := map[string]*Summary{}
+	for _, s := range sessions {
+		sum, ok := byGame[s.Game]
+		if !ok {
+			sum = &Summary{Game: s.Game, Outcomes: map[string]int{}, Matches: map[string]int{}}
+			byGame[s.Game] = sum
+		}
+
+		sum.Time += s.Duration()
+		sum.Keys += s.Keys
+		if s.Saved {
+			continue
+		}
+
+		sum.Played++
+		sum.Outcomes[s.Outcome]++
+		for _, m := range s.Matches {
+			sum.Matches[m]++
+		}
+		if s.Outcome == game.Won.String() {
+			sum.WonTime += s.GameDuration()
+		}
+		if sum.Played == 1 || s.Score > sum.Best {
+			sum.Best = s.Score
+		}
+	}
+
+	summaries := make([]Summary, 0, len(byGame))
+	for _, sum := range byGame {
+		summaries = append(summaries, *sum)
+	}
+	slices.SortFunc(summaries, func(a, b Summary) int {
+		if c := cmp.Compare(b.Time, a.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Game, b.Game)
+	})
+
+	return summaries
+}
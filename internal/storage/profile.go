@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync/atomic"
+)
+
+// DefaultProfile is the profile used until another is chosen. Its files are
+// where gg kept them before it had profiles.
+const DefaultProfile = "default"
+
+// profile is the name of the current profile.
+var profile atomic.Value
+
+// Profile returns the name of the current profile.
+func Profile() string {
+	if name, ok := profile.Load().(string); ok {
+		return name
+	}
+	return DefaultProfile
+}
+
+// SetProfile makes name the current profile, so the data and settings of
+// that profile are used from now on. It doesn't create the profile; see
+// CreateProfile.
+//
+// The profile is the same for the whole process: everything played in it
+// counts for the profile last set.
+func SetProfile(name string) error {
+	if err := CheckProfile(name); err != nil {
+		return err
+	}
+
+	profile.Store(name)
+	return nil
+}
+
+var profileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// CheckProfile reports whether name can be used as the name of a profile:
+// up to 32 lowercase letters, digits, dashes and underscores.
+func CheckProfile(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("%q can't be the name of a profile; use up to 32 lowercase letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// profilesDir is the directory under dir that holds the profiles other than
+// the default.
+const profilesDir = "profiles"
+
+// profileDir returns the directory of the current profile inside dir, one of
+// the shared directories.
+func profileDir(dir string) string {
+	if name := Profile(); name != DefaultProfile {
+		return filepath.Join(dir, profilesDir, name)
+	}
+	return dir
+}
+
+// Profiles returns the names of every profile, the default first and then
+// the others in order.
+func Profiles() ([]string, error) {
+	dir, err := SharedDataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, profilesDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && CheckProfile(e.Name()) == nil && e.Name() != DefaultProfile {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// CreateProfile adds a profile called name, so it is listed by Profiles
+// before anything is stored in it.
+func CreateProfile(name string) error {
+	if err := CheckProfile(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		return nil
+	}
+
+	dir, err := SharedDataDir()
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(filepath.Join(dir, profilesDir, name), 0o755)
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCheckProfile(t *testing.T) {
+	for _, name := range []string{"default", "ann", "team-2", "a_b", "0"} {
+		if err := CheckProfile(name); err != nil {
+			t.Errorf("CheckProfile(%q) = %v, want nil", name, err)
+		}
+	}
+
+	for _, name := range []string{"", "Ann", "-ann", "../ann", "a b", "ann/bob", "abcdefghijklmnopqrstuvwxyz0123456"} {
+		if err := CheckProfile(name); err == nil {
+			t.Errorf("CheckProfile(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestProfileDirs(t *testing.T) {
+	data, config := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Cleanup(func() { SetProfile(DefaultProfile) })
+
+	// The default profile keeps the files gg had before profiles.
+	if dir, _ := DataDir(); dir != filepath.Join(data, "gg") {
+		t.Errorf("default DataDir() = %q", dir)
+	}
+
+	if err := SetProfile("ann"); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := DataDir(); dir != filepath.Join(data, "gg", "profiles", "ann") {
+		t.Errorf("DataDir() of ann = %q", dir)
+	}
+	if dir, _ := ConfigDir(); dir != filepath.Join(config, "gg", "profiles", "ann") {
+		t.Errorf("ConfigDir() of ann = %q", dir)
+	}
+	if dir, _ := SharedDataDir(); dir != filepath.Join(data, "gg") {
+		t.Errorf("SharedDataDir() of ann = %q", dir)
+	}
+
+	if err := SetProfile("Not Valid"); err == nil {
+		t.Error("SetProfile accepted an invalid name")
+	}
+	if Profile() != "ann" {
+		t.Errorf("an invalid name changed the profile to %q", Profile())
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if names, err := Profiles(); err != nil || !slices.Equal(names, []string{DefaultProfile}) {
+		t.Errorf("Profiles() = %v, %v before any were made", names, err)
+	}
+
+	for _, name := range []string{"zoe", "ann", DefaultProfile} {
+		if err := CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{DefaultProfile, "ann", "zoe"}
+	if names, err := Profiles(); err != nil || !slices.Equal(names, want) {
+		t.Errorf("Profiles() = %v, %v, want %v", names, err, want)
+	}
+}
@@ -0,0 +1,144 @@
+// Package storage finds where gg keeps its files and writes them safely.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// DataDir returns the directory the current profile's data is stored in.
+// The default profile uses SharedDataDir itself. The directory is not
+// created.
+func DataDir() (string, error) {
+	dir, err := SharedDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return profileDir(dir), nil
+}
+
+// ConfigDir returns the directory the current profile's settings are read
+// from. The default profile uses SharedConfigDir itself. The directory is
+// not created.
+func ConfigDir() (string, error) {
+	dir, err := SharedConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return profileDir(dir), nil
+}
+
+// SharedDataDir returns the directory gg stores its data in, following the
+// XDG base directory spec. Data that belongs to no profile in particular
+// goes here. The directory is not created.
+func SharedDataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "gg"), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "gg"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share", "gg"), nil
+}
+
+// SharedConfigDir returns the directory gg reads its settings from,
+// following the XDG base directory spec. The directory is not created.
+func SharedConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gg"), nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gg"), nil
+}
+
+// WriteFile writes data to path atomically: it is written to a temporary
+// file in the same directory first, which is then renamed over path. Readers
+// see either the old or the new contents, never a mix.
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+const (
+	lockTimeout = 5 * time.Second
+	// A lock older than this was left behind by a process that died.
+	lockStale = 30 * time.Second
+)
+
+// Lock takes an exclusive lock on path by creating path+".lock", waiting for
+// other processes to release it if needed. The returned function releases
+// the lock.
+func Lock(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	lock := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lock)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s", lock)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
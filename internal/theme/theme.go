@@ -0,0 +1,242 @@
+// Package theme holds the colours games are drawn with. Games style things
+// by what they are, like the first player or a wall, and the theme the
+// player chose decides what that looks like.
+package theme
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/settings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme maps each role to a style.
+type Theme struct {
+	Name string
+
+	Player1   lipgloss.Style // The first player, or the only one.
+	Player2   lipgloss.Style
+	Wall      lipgloss.Style // Walls, borders and obstacles.
+	Food      lipgloss.Style // Things to collect.
+	Highlight lipgloss.Style // The selected cell, a winner and other things to notice.
+	Status    lipgloss.Style // Scores and messages below the game.
+	Error     lipgloss.Style
+	Board     lipgloss.Style // The background of boards and the text on them.
+
+	// Tiles are the 2048 tiles: the empty tile, then 2, 4, 8 and so on up
+	// to 2048.
+	Tiles []lipgloss.Style
+}
+
+// Names lists the built-in themes.
+var Names = []string{"default", "high-contrast", "solarized", "monochrome"}
+
+// Get returns the theme called name.
+func Get(name string) (Theme, bool) {
+	switch name {
+	case "default":
+		return defaultTheme(), true
+	case "high-contrast":
+		return highContrast(), true
+	case "solarized":
+		return solarized(), true
+	case "monochrome":
+		return monochrome(), true
+	}
+
+	return Theme{}, false
+}
+
+// override is the theme chosen on the command line, if any.
+var override string
+
+// Set makes Current return the theme called name, whatever the settings
+// say.
+func Set(name string) error {
+	if !slices.Contains(Names, name) {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+
+	override = name
+	return nil
+}
+
+// Check reports whether the theme in the settings can be used.
+func Check() error {
+	s, err := settings.Read()
+	if err != nil {
+		return err
+	}
+
+	if s.Theme != "" && !slices.Contains(Names, s.Theme) {
+		return fmt.Errorf("unknown theme %q", s.Theme)
+	}
+
+	return nil
+}
+
+// Current returns the theme the player chose, or the default theme if the
+// choice can't be read.
+func Current() Theme {
+	name := override
+	if name == "" {
+		s, _ := settings.Read()
+		name = s.Theme
+	}
+
+	if t, ok := Get(name); ok {
+		return t
+	}
+	return defaultTheme()
+}
+
+func fg(c string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+}
+
+// tile returns the style of a 2048 tile.
+func tile(bg, text string) lipgloss.Style {
+	return lipgloss.NewStyle().Background(lipgloss.Color(bg)).Foreground(lipgloss.Color(text))
+}
+
+func defaultTheme() Theme {
+	const light, dark = "#f9f6f2", "#000000"
+
+	return Theme{
+		Name:      "default",
+		Player1:   fg("#ff9e3b"),
+		Player2:   fg("#7e9cd8"),
+		Wall:      fg("#cccccc"),
+		Food:      fg("#ff0000"),
+		Highlight: fg("#98bb6c").Bold(true),
+		Status:    fg("#7e9cd8"),
+		Error:     fg("9"),
+		Board:     tile("#3c3a32", "#dcd7ba"),
+		Tiles: []lipgloss.Style{
+			tile("#3c3a32", light),
+			tile("#eee4da", dark),
+			tile("#ede0c8", dark),
+			tile("#f2b179", light),
+			tile("#f59563", light),
+			tile("#f67c5f", light),
+			tile("#f65e3b", light),
+			tile("#edcf72", light),
+			tile("#edcc61", light),
+			tile("#edc850", light),
+			tile("#edc53f", light),
+			tile("#edc22e", light),
+		},
+	}
+}
+
+// highContrast only uses the 16 basic colours, which every terminal can
+// show and most players have tuned to be readable.
+func highContrast() Theme {
+	bold := func(c string) lipgloss.Style {
+		return fg(c).Bold(true)
+	}
+	tiles := []lipgloss.Style{tile("0", "15")}
+	for _, bg := range []string{"7", "15", "11", "3", "10", "2", "14", "6", "12", "13", "9"} {
+		tiles = append(tiles, tile(bg, "0").Bold(true))
+	}
+
+	return Theme{
+		Name:      "high-contrast",
+		Player1:   bold("11"),
+		Player2:   bold("14"),
+		Wall:      fg("15"),
+		Food:      bold("9"),
+		Highlight: bold("10"),
+		Status:    fg("15"),
+		Error:     bold("9"),
+		Board:     tile("0", "15"),
+		Tiles:     tiles,
+	}
+}
+
+// solarized uses Ethan Schoonover's Solarized palette.
+func solarized() Theme {
+	const (
+		base03  = "#002b36"
+		base02  = "#073642"
+		base01  = "#586e75"
+		base1   = "#93a1a1"
+		base3   = "#fdf6e3"
+		yellow  = "#b58900"
+		orange  = "#cb4b16"
+		red     = "#dc322f"
+		magenta = "#d33682"
+		violet  = "#6c71c4"
+		blue    = "#268bd2"
+		cyan    = "#2aa198"
+		green   = "#859900"
+	)
+
+	return Theme{
+		Name:      "solarized",
+		Player1:   fg(yellow),
+		Player2:   fg(blue),
+		Wall:      fg(base01),
+		Food:      fg(red),
+		Highlight: fg(green).Bold(true),
+		Status:    fg(cyan),
+		Error:     fg(red),
+		Board:     tile(base02, base1),
+		Tiles: []lipgloss.Style{
+			tile(base02, base1),
+			tile(base01, base3),
+			tile(base1, base03),
+			tile(yellow, base3),
+			tile(orange, base3),
+			tile(red, base3),
+			tile(magenta, base3),
+			tile(violet, base3),
+			tile(blue, base3),
+			tile(cyan, base3),
+			tile(green, base3),
+			tile(yellow, base03).Bold(true),
+		},
+	}
+}
+
+// monochrome uses no colour at all, only bold, faint and reversed text.
+func monochrome() Theme {
+	plain := lipgloss.NewStyle()
+
+	tiles := []lipgloss.Style{plain.Faint(true)}
+	for i := 1; i <= 11; i++ {
+		switch {
+		case i == 11:
+			tiles = append(tiles, plain.Reverse(true).Bold(true))
+		case i >= 7:
+			tiles = append(tiles, plain.Bold(true))
+		default:
+			tiles = append(tiles, plain)
+		}
+	}
+
+	return Theme{
+		Name:      "monochrome",
+		Player1:   plain.Bold(true),
+		Player2:   plain,
+		Wall:      plain,
+		Food:      plain.Bold(true),
+		Highlight: plain.Bold(true),
+		Status:    plain.Faint(true),
+		Error:     plain.Bold(true),
+		Board:     plain,
+		Tiles:     tiles,
+	}
+}
+
+// Tile returns the style of the 2048 tile with value, or of the empty tile
+// for 0.
+func (t Theme) Tile(value int) lipgloss.Style {
+	i := 0
+	for v := value; v > 1 && i < len(t.Tiles)-1; v /= 2 {
+		i++
+	}
+	return t.Tiles[i]
+}
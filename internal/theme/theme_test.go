@@ -0,0 +1,80 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestThemes(t *testing.T) {
+	for _, name := range Names {
+		th, ok := Get(name)
+		if !ok {
+			t.Fatalf("Get(%q) found no theme", name)
+		}
+		if th.Name != name {
+			t.Errorf("Get(%q).Name = %q", name, th.Name)
+		}
+		if len(th.Tiles) != 12 {
+			t.Errorf("%s has %d tiles, want 12", name, len(th.Tiles))
+		}
+	}
+
+	if _, ok := Get("nope"); ok {
+		t.Error(`Get("nope") found a theme`)
+	}
+}
+
+func TestTile(t *testing.T) {
+	th := defaultTheme()
+
+	tests := []struct {
+		value int
+		want  int
+	}{
+		{0, 0},
+		{2, 1},
+		{4, 2},
+		{1024, 10},
+		{2048, 11},
+		{8192, 11},
+	}
+
+	for _, tt := range tests {
+		got := th.Tile(tt.value).GetBackground()
+		if want := th.Tiles[tt.want].GetBackground(); got != want {
+			t.Errorf("Tile(%d) = %v, want tile %d (%v)", tt.value, got, tt.want, want)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Cleanup(func() { override = "" })
+
+	if got := Current().Name; got != "default" {
+		t.Errorf("without a config, Current() = %q, want default", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "gg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gg", "config.json"), []byte(`{"theme": "solarized"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Current().Name; got != "solarized" {
+		t.Errorf("Current() = %q, want solarized", got)
+	}
+
+	if err := Set("monochrome"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Current().Name; got != "monochrome" {
+		t.Errorf("after Set, Current() = %q, want monochrome", got)
+	}
+
+	if err := Set("nope"); err == nil {
+		t.Error(`Set("nope") succeeded`)
+	}
+}
@@ -0,0 +1,102 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Algorithms lists the names accepted by Generate and NewGenerator.
+var Algorithms = []string{"prim"}
+
+// Generate returns a new maze width cells wide and height cells high,
+// generated with algorithm, one of Algorithms. Every random choice comes from
+// rnd.
+func Generate(rnd *rand.Rand, width, height int, algorithm string) (*Maze, error) {
+	generator, err := NewGenerator(rnd, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	maze, err := New(rnd, width, height)
+	if err != nil {
+		return nil, err
+	}
+	generator.Generate(maze)
+
+	return maze, nil
+}
+
+// Generator carves the paths of a maze.
+type Generator interface {
+	// Generate carves paths from the start of maze, a maze of walls as New
+	// returns it, and sets its end.
+	Generate(maze *Maze)
+}
+
+// NewGenerator returns the generator that uses algorithm, one of Algorithms.
+func NewGenerator(rnd *rand.Rand, algorithm string) (Generator, error) {
+	switch algorithm {
+	case "prim":
+		return &PrimGenerator{rnd: rnd}, nil
+	default:
+		return nil, fmt.Errorf("unknown maze algorithm %q", algorithm)
+	}
+}
+
+// PrimGenerator generates mazes with a randomised version of Prim's
+// algorithm, and puts the end of the maze as far from its start as it can.
+type PrimGenerator struct {
+	rnd *rand.Rand
+}
+
+func (p *PrimGenerator) Generate(maze *Maze) {
+	start := maze.Start
+	curr := start
+
+	walls := maze.frontiers(start.X, start.Y, true)
+	visited := make(map[Cell]bool)
+	for _, wall := range walls {
+		visited[wall] = true
+	}
+
+	for len(walls) > 0 {
+		// Pop random wall
+		randIdx := p.rnd.IntN(len(walls))
+		wall := walls[randIdx]
+		walls = append(walls[:randIdx], walls[randIdx+1:]...)
+
+		if maze.Get(wall.X, wall.Y) == Path {
+			continue
+		}
+
+		paths := maze.frontiers(wall.X, wall.Y, false)
+		if len(paths) == 0 {
+			continue
+		}
+		path := paths[p.rnd.IntN(len(paths))]
+
+		// skip special case: last wall before boundary
+		if wall.Diff(path) != 1 {
+			// Connect wall and path
+			x, y := (wall.X+path.X)/2, (wall.Y+path.Y)/2
+			between := Cell{x, y}
+			maze.makePath(between)
+		}
+
+		maze.makePath(wall)
+		// Add walls
+		neighbors := maze.frontiers(wall.X, wall.Y, true)
+		for _, neighbor := range neighbors {
+			if !visited[neighbor] {
+				visited[neighbor] = true
+				walls = append(walls, neighbor)
+			}
+		}
+
+		// find the longest point
+		if !maze.IsBoundary(wall.X, wall.Y) && wall.Diff(start) > curr.Diff(start) {
+			curr = wall
+		}
+	}
+
+	maze.SetEnd(curr.X, curr.Y)
+}
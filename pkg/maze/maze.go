@@ -0,0 +1,197 @@
+// Package maze generates mazes and finds the way through them. It is what
+// gg's maze game plays on.
+//
+// A maze is a grid of runes: walls, paths, a start and an end. Generating
+// one with the same random source always gives the same maze:
+//
+//	m, err := maze.Generate(rand.New(rand.NewPCG(1, 2)), 25, 15, "prim")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Print(m)
+//	fmt.Println(maze.ShortestPath(m.Grid), "moves")
+package maze
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// The runes a maze is made of.
+const (
+	Wall  = '#'
+	Path  = ' '
+	Start = 'S'
+	End   = 'E'
+)
+
+// MinSize is the fewest cells a maze can be wide or high: an outer wall
+// around room for a start and an end.
+const MinSize = 5
+
+// Cell is a position in a maze, X from the left and Y from the top.
+type Cell struct {
+	X, Y int
+}
+
+// dirs are the directions to the neighbours of a cell.
+var dirs = []Cell{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+
+// Diff returns the square of the distance between c and other.
+func (c Cell) Diff(other Cell) int {
+	dx := c.X - other.X
+	dy := c.Y - other.Y
+	return dx*dx + dy*dy
+}
+
+// Maze is a maze, its Grid indexed by row and then column.
+type Maze struct {
+	Width, Height int
+	Start, End    Cell
+	Grid          [][]rune
+}
+
+// New returns a maze of walls, but for its start, placed at random in the
+// top left quarter. It is for a Generator to carve the paths. The width and
+// height must be at least MinSize.
+func New(rnd *rand.Rand, width, height int) (*Maze, error) {
+	if width < MinSize || height < MinSize {
+		return nil, fmt.Errorf("maze of %dx%d is too small, it must be at least %dx%d", width, height, MinSize, MinSize)
+	}
+
+	grid := make([][]rune, height)
+
+	for i := range grid {
+		grid[i] = make([]rune, width)
+		for j := range grid[i] {
+			grid[i][j] = Wall
+		}
+	}
+
+	startX := rnd.IntN(width/4) + 1
+	startY := rnd.IntN(height/4) + 1
+
+	grid[startY][startX] = Start
+
+	return &Maze{
+		Width:  width,
+		Height: height,
+		Start:  Cell{startX, startY},
+		Grid:   grid,
+	}, nil
+}
+
+// Set sets the cell at x, y to val.
+func (m *Maze) Set(x, y int, val rune) {
+	m.Grid[y][x] = val
+}
+
+// Get returns the cell at x, y.
+func (m Maze) Get(x, y int) rune {
+	return m.Grid[y][x]
+}
+
+// SetEnd makes the cell at x, y the end of the maze.
+func (m *Maze) SetEnd(x, y int) {
+	m.Grid[y][x] = End
+	m.End = Cell{x, y}
+}
+
+// IsInner reports whether x, y is inside the maze's outer wall.
+func (m Maze) IsInner(x, y int) bool {
+	return x > 0 && x < m.Width-1 && y > 0 && y < m.Height-1
+}
+
+// IsBoundary reports whether x, y is on the maze's outer wall.
+func (m Maze) IsBoundary(x, y int) bool {
+	vertical := (x == 0 || x == m.Width-1) && y >= 0 && y <= m.Height-1
+	horizontal := (y == 0 || y == m.Height-1) && x >= 0 && x <= m.Width-1
+
+	return vertical || horizontal
+}
+
+// IsWall reports whether the cell at x, y is a wall.
+func (m Maze) IsWall(x, y int) bool {
+	return m.Grid[y][x] == Wall
+}
+
+// frontiers returns the cells two steps away from x, y, past a wall, that
+// are walls if findWall is set, and paths otherwise.
+func (m Maze) frontiers(x, y int, findWall bool) []Cell {
+	var frontiers []Cell
+	for _, dir := range dirs {
+		dx, dy := x+2*dir.X, y+2*dir.Y
+		if !m.IsInner(dx, dy) {
+			if findWall && m.IsBoundary(dx, dy) {
+				frontiers = append(frontiers, Cell{dx, dy})
+			}
+			continue
+		}
+		if m.IsWall(dx, dy) == findWall && m.IsWall(x+dir.X, y+dir.Y) {
+			frontiers = append(frontiers, Cell{dx, dy})
+		}
+	}
+
+	return frontiers
+}
+
+// makePath turns the wall at cell into a path, unless it is on the outer
+// wall.
+func (m *Maze) makePath(cell Cell) {
+	if !m.IsInner(cell.X, cell.Y) || !m.IsWall(cell.X, cell.Y) {
+		return
+	}
+	m.Set(cell.X, cell.Y, Path)
+}
+
+// String draws the maze a row per line.
+func (m Maze) String() string {
+	var s strings.Builder
+	for _, row := range m.Grid {
+		s.WriteString(string(row))
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// ShortestPath returns the fewest moves it takes to get from the Start of
+// grid to its End, moving up, down, left or right and never into a Wall, or
+// -1 if there is no way through.
+func ShortestPath(grid [][]rune) int {
+	start, found := Cell{}, false
+	for y, row := range grid {
+		for x, c := range row {
+			if c == Start {
+				start, found = Cell{x, y}, true
+			}
+		}
+	}
+	if !found {
+		return -1
+	}
+
+	dist := map[Cell]int{start: 0}
+	queue := []Cell{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if grid[p.Y][p.X] == End {
+			return dist[p]
+		}
+
+		for _, d := range dirs {
+			next := Cell{p.X + d.X, p.Y + d.Y}
+			if next.Y < 0 || next.Y >= len(grid) || next.X < 0 || next.X >= len(grid[next.Y]) {
+				continue
+			}
+			if _, seen := dist[next]; seen || grid[next.Y][next.X] == Wall {
+				continue
+			}
+			dist[next] = dist[p] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	return -1
+}
@@ -1,18 +1,45 @@
-package mazegenerator
+package maze
 
 import (
+	"math/rand/v2"
+	"slices"
 	"testing"
 )
 
+func testRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+// newMaze returns newMaze(t, width, height), failing t on an error.
+func newMaze(t *testing.T, width, height int) *Maze {
+	t.Helper()
+	maze, err := New(testRand(), width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return maze
+}
+
+// generate returns a maze generated with Prim's algorithm, failing t on an
+// error.
+func generate(t *testing.T, rnd *rand.Rand, width, height int) *Maze {
+	t.Helper()
+	maze, err := Generate(rnd, width, height, "prim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return maze
+}
+
 func TestPathFinder(t *testing.T) {
 	t.Run("Testing path finder on blocked maze", func(t *testing.T) {
-		maze := NewMaze(25, 25)
+		maze := newMaze(t, 25, 25)
 
-		startX, startY := maze.GetStartPos()
+		startX, startY := maze.Start.X, maze.Start.Y
 		endX, endY := 5, 5
 
 		if isPathExists(maze, startX, startY, endX, endY) {
-			maze.Print()
+			t.Log("\n" + maze.String())
 			t.Errorf("No path should exist")
 		}
 	})
@@ -20,7 +47,7 @@ func TestPathFinder(t *testing.T) {
 	t.Run("Testing path finder on valid maze", func(t *testing.T) {
 		for _, grid := range mazes {
 			width, height := len(grid[0]), len(grid)
-			maze := NewMaze(width, height)
+			maze := newMaze(t, width, height)
 			for i := range grid {
 				for j := range grid[i] {
 					maze.Set(j, i, grid[i][j])
@@ -33,15 +60,15 @@ func TestPathFinder(t *testing.T) {
 				}
 			}
 
-			startX, startY := maze.GetStartPos()
-			endX, endY := maze.GetEndPos()
+			startX, startY := maze.Start.X, maze.Start.Y
+			endX, endY := maze.End.X, maze.End.Y
 
 			if startX == endX && startY == endY {
 				t.Errorf("Start and end positions overlap")
 			}
 
 			if !isPathExists(maze, startX, startY, endX, endY) {
-				maze.Print()
+				t.Log("\n" + maze.String())
 				t.Errorf("No valid path found")
 			}
 		}
@@ -50,7 +77,7 @@ func TestPathFinder(t *testing.T) {
 	t.Run("Testing path finder on invalid maze", func(t *testing.T) {
 		for _, grid := range invalidMazes {
 			width, height := len(grid[0]), len(grid)
-			maze := NewMaze(width, height)
+			maze := newMaze(t, width, height)
 			for i := range grid {
 				for j := range grid[i] {
 					maze.Set(j, i, grid[i][j])
@@ -63,11 +90,11 @@ func TestPathFinder(t *testing.T) {
 				}
 			}
 
-			startX, startY := maze.GetStartPos()
-			endX, endY := maze.GetEndPos()
+			startX, startY := maze.Start.X, maze.Start.Y
+			endX, endY := maze.End.X, maze.End.Y
 
 			if isPathExists(maze, startX, startY, endX, endY) {
-				maze.Print()
+				t.Log("\n" + maze.String())
 				t.Errorf("No valid path should exist")
 			}
 		}
@@ -75,25 +102,81 @@ func TestPathFinder(t *testing.T) {
 }
 
 func TestMazePath(t *testing.T) {
+	rnd := testRand()
 	for i := 0; i < 1000; i++ {
 		t.Run("Testing maze", func(t *testing.T) {
-			maze := GenerateMaze(25, 15, "prim")
+			maze := generate(t, rnd, 25, 15)
 
-			startX, startY := maze.GetStartPos()
-			endX, endY := maze.GetEndPos()
+			startX, startY := maze.Start.X, maze.Start.Y
+			endX, endY := maze.End.X, maze.End.Y
 
 			if startX == endX && startY == endY {
 				t.Errorf("Start and end positions overlap")
 			}
 
 			if !isPathExists(maze, startX, startY, endX, endY) {
-				maze.Print()
+				t.Log("\n" + maze.String())
 				t.Errorf("No valid path found")
 			}
 		})
 	}
 }
 
+func TestSameSeed(t *testing.T) {
+	a := generate(t, rand.New(rand.NewPCG(42, 42)), 41, 21)
+	b := generate(t, rand.New(rand.NewPCG(42, 42)), 41, 21)
+
+	for y := range a.Grid {
+		if !slices.Equal(a.Grid[y], b.Grid[y]) {
+			t.Log("\n" + a.String() + "\n" + b.String())
+			t.Fatalf("mazes generated with the same seed differ on row %d", y)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, size := range [][2]int{{0, 15}, {25, -1}, {MinSize - 1, MinSize}, {MinSize, MinSize - 1}} {
+		if _, err := Generate(testRand(), size[0], size[1], "prim"); err == nil {
+			t.Errorf("a maze of %dx%d was generated", size[0], size[1])
+		}
+	}
+	if _, err := Generate(testRand(), 25, 15, "kruskal"); err == nil {
+		t.Error("a maze was generated with an unknown algorithm")
+	}
+
+	for w := MinSize; w < MinSize+4; w++ {
+		for h := MinSize; h < MinSize+4; h++ {
+			if moves := ShortestPath(generate(t, testRand(), w, h).Grid); moves < 1 {
+				t.Errorf("a maze of %dx%d has %d moves to its end", w, h, moves)
+			}
+		}
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	grid := [][]rune{
+		[]rune("#######"),
+		[]rune("#S    #"),
+		[]rune("# ### #"),
+		[]rune("#    E#"),
+		[]rune("#######"),
+	}
+	if got := ShortestPath(grid); got != 6 {
+		t.Errorf("got %d moves, want 6", got)
+	}
+
+	for _, grid := range mazes {
+		if ShortestPath(grid) < 0 {
+			t.Errorf("no way was found through\n%s", (&Maze{Grid: grid}).String())
+		}
+	}
+	for _, grid := range invalidMazes {
+		if n := ShortestPath(grid); n != -1 {
+			t.Errorf("a way of %d moves was found through\n%s", n, (&Maze{Grid: grid}).String())
+		}
+	}
+}
+
 func isPathExists(maze *Maze, startX, startY, endX, endY int) bool {
 	visited := make(map[Cell]bool)
 	var dfs func(x, y int) bool
@@ -106,18 +189,18 @@ func isPathExists(maze *Maze, startX, startY, endX, endY int) bool {
 		visited[Cell{x, y}] = true
 		maze.Set(x, y, '*')
 
-		for _, dir := range DIRS {
-			neighbor := Cell{x + dir.x, y + dir.y}
+		for _, dir := range dirs {
+			neighbor := Cell{x + dir.X, y + dir.Y}
 			// Check boundary
-			if !maze.IsInner(neighbor.x, neighbor.y) && !maze.IsBoundary(neighbor.x, neighbor.y) {
+			if !maze.IsInner(neighbor.X, neighbor.Y) && !maze.IsBoundary(neighbor.X, neighbor.Y) {
 				continue
 			}
 			// Skip visited cells and walls
-			if visited[neighbor] || maze.IsWall(neighbor.x, neighbor.y) {
+			if visited[neighbor] || maze.IsWall(neighbor.X, neighbor.Y) {
 				continue
 			}
 
-			if dfs(neighbor.x, neighbor.y) {
+			if dfs(neighbor.X, neighbor.Y) {
 				return true
 			}
 
@@ -0,0 +1,187 @@
+// Package sudoku generates and solves sudoku puzzles, the ones gg's sudoku
+// game deals out.
+//
+// A grid is 9 rows of 9 numbers, with 0 for an empty cell:
+//
+//	grid, err := sudoku.Generate(rand.New(rand.NewPCG(1, 2)), 40)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	sudoku.Solve(grid)
+package sudoku
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// Generate returns a puzzle with holes of its 81 cells empty, from 0 to 81.
+// Every random choice comes from rnd, so the same seed gives the same puzzle.
+func Generate(rnd *rand.Rand, holes int) ([][]int, error) {
+	if holes < 0 || holes > 81 {
+		return nil, fmt.Errorf("a sudoku can't have %d empty cells, only 0 to 81", holes)
+	}
+
+	g := generator{rnd: rnd}
+	g.grid = make([][]int, 9)
+	for i := range g.grid {
+		g.grid[i] = make([]int, 9)
+	}
+
+	g.generate()
+	g.emptyCells(holes)
+	return g.grid, nil
+}
+
+// Solve fills the empty cells of grid, and reports whether it could. A grid
+// that isn't 9 rows of 9 numbers from 0 to 9, or whose numbers already clash,
+// can't be solved.
+func Solve(grid [][]int) bool {
+	if !valid(grid) {
+		return false
+	}
+
+	g := generator{grid: grid}
+	for r, row := range grid {
+		for c, n := range row {
+			if n != 0 && !Allowed(grid, r, c, n) {
+				return false
+			}
+		}
+	}
+
+	return g.fillRemaining(0, 0)
+}
+
+// Allowed reports whether n can go in the cell at row, col of grid without
+// clashing with another number in its row, column or box. The cell itself
+// is left out. Nothing is allowed in a grid that isn't 9 rows of 9 numbers
+// from 0 to 9, in a cell off the grid, or if n isn't from 1 to 9.
+func Allowed(grid [][]int, row, col, n int) bool {
+	if !valid(grid) || row < 0 || row >= 9 || col < 0 || col >= 9 || n < 1 || n > 9 {
+		return false
+	}
+
+	g := generator{grid: grid}
+
+	old := grid[row][col]
+	grid[row][col] = 0
+	defer func() { grid[row][col] = old }()
+
+	return g.isSafe(row, col, n)
+}
+
+// valid reports whether grid is 9 rows of 9 numbers from 0 to 9.
+func valid(grid [][]int) bool {
+	if len(grid) != 9 {
+		return false
+	}
+	for _, row := range grid {
+		if len(row) != 9 {
+			return false
+		}
+		for _, n := range row {
+			if n < 0 || n > 9 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// generator fills a grid.
+type generator struct {
+	grid [][]int
+	rnd  *rand.Rand
+}
+
+func (m *generator) unusedInBox(row, col, n int) bool {
+	for i := range 3 {
+		for j := range 3 {
+			if m.grid[row+i][col+j] == n {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (m *generator) fillBox(row, col int) {
+	var n int
+	for i := range 3 {
+		for j := range 3 {
+			for !m.unusedInBox(row, col, n) {
+				n = m.rnd.IntN(9) + 1
+			}
+			m.grid[row+i][col+j] = n
+		}
+	}
+}
+
+func (m *generator) unusedInCol(col, n int) bool {
+	for j := range 9 {
+		if m.grid[j][col] == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *generator) unusedInRow(row, n int) bool {
+	return !slices.Contains(m.grid[row], n)
+}
+
+func (m *generator) isSafe(row, col, n int) bool {
+	return m.unusedInBox(row-row%3, col-col%3, n) && m.unusedInCol(col, n) && m.unusedInRow(row, n)
+}
+
+func (m *generator) fillRemaining(row, col int) bool {
+	if row == 9 {
+		return true
+	}
+
+	if col == 9 {
+		return m.fillRemaining(row+1, 0)
+	}
+
+	if m.grid[row][col] != 0 {
+		return m.fillRemaining(row, col+1)
+	}
+
+	for n := 1; n < 10; n++ {
+		if m.isSafe(row, col, n) {
+			m.grid[row][col] = n
+			if m.fillRemaining(row, col+1) {
+				return true
+			}
+			m.grid[row][col] = 0
+		}
+	}
+
+	return false
+}
+
+func (m *generator) emptyCells(amount int) {
+	for amount > 0 {
+		id := m.rnd.IntN(81)
+		i := id / 9
+		j := id % 9
+
+		if m.grid[i][j] != 0 {
+			m.grid[i][j] = 0
+			amount--
+		}
+	}
+}
+
+func (m *generator) generate() {
+	for i := 0; i < 9; i += 3 {
+		m.fillBox(i, i)
+	}
+
+	m.fillRemaining(0, 0)
+}
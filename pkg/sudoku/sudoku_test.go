@@ -0,0 +1,120 @@
+package sudoku
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// generate returns Generate(rand.New(rand.NewPCG(seed, seed)), holes),
+// failing t on an error.
+func generate(t *testing.T, seed uint64, holes int) [][]int {
+	t.Helper()
+	grid, err := Generate(rand.New(rand.NewPCG(seed, seed)), holes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grid
+}
+
+func TestGen(t *testing.T) {
+	grid := generate(t, 1, 0)
+
+	for r, row := range grid {
+		for c, cell := range row {
+			if !Allowed(grid, r, c, cell) {
+				t.Fatalf("Invalid Sudoku generated: %d overlaps", cell)
+			}
+		}
+	}
+
+	grid = generate(t, 1, 20)
+	c := 0
+	for _, r := range grid {
+		for _, n := range r {
+			if n == 0 {
+				c++
+			}
+		}
+	}
+
+	if c != 20 {
+		t.Fatalf("Not enough empty cells: wanted=20 got=%d", c)
+	}
+
+	for _, holes := range []int{-1, 82} {
+		if _, err := Generate(rand.New(rand.NewPCG(1, 2)), holes); err == nil {
+			t.Errorf("a puzzle with %d empty cells was generated", holes)
+		}
+	}
+	for _, row := range generate(t, 1, 81) {
+		if !slices.Equal(row, make([]int, 9)) {
+			t.Fatalf("a puzzle with 81 empty cells has the row %v", row)
+		}
+	}
+}
+
+func TestSameSeed(t *testing.T) {
+	a := generate(t, 42, 54)
+	b := generate(t, 42, 54)
+
+	for i := range a {
+		if !slices.Equal(a[i], b[i]) {
+			t.Fatalf("grids generated with the same seed differ on row %d", i)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	grid := generate(t, 1, 54)
+	given := make([][]int, 9)
+	for i, row := range grid {
+		given[i] = slices.Clone(row)
+	}
+
+	if !Solve(grid) {
+		t.Fatal("a generated puzzle couldn't be solved")
+	}
+	for r, row := range grid {
+		for c, n := range row {
+			if n == 0 || !Allowed(grid, r, c, n) {
+				t.Fatalf("the solution has %d at row %d, column %d", n, r, c)
+			}
+			if given[r][c] != 0 && given[r][c] != n {
+				t.Fatalf("solving changed row %d, column %d", r, c)
+			}
+		}
+	}
+
+	given[0][0], given[0][1] = 5, 5
+	if Solve(given) {
+		t.Error("a puzzle with two 5s in its first row was solved")
+	}
+}
+
+func TestBadGrids(t *testing.T) {
+	grid := generate(t, 1, 40)
+	short := grid[:8]
+	narrow := slices.Clone(grid)
+	narrow[3] = narrow[3][:8]
+	big := make([][]int, 9)
+	for i, row := range grid {
+		big[i] = slices.Clone(row)
+	}
+	big[4][4] = 10
+
+	for name, g := range map[string][][]int{"8 rows": short, "a row of 8": narrow, "a 10": big, "nil": nil} {
+		if Solve(g) {
+			t.Errorf("a grid with %s was solved", name)
+		}
+		if Allowed(g, 0, 0, 1) {
+			t.Errorf("a 1 is allowed in a grid with %s", name)
+		}
+	}
+
+	for _, cell := range [][3]int{{-1, 0, 1}, {0, 9, 1}, {9, 0, 1}, {0, 0, 0}, {0, 0, 10}} {
+		if Allowed(grid, cell[0], cell[1], cell[2]) {
+			t.Errorf("Allowed(grid, %d, %d, %d) is true", cell[0], cell[1], cell[2])
+		}
+	}
+}
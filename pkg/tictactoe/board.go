@@ -0,0 +1,125 @@
+// Package tictactoe is tictactoe on a square board of any size, and an AI
+// that plays it with Monte Carlo tree search. It is the engine behind gg's
+// tictactoe-ai, without the terminal interface.
+//
+// A game is a Board, changed by playing moves with Rules:
+//
+//	board := tictactoe.NewBoard(3)
+//	rules := tictactoe.Rules{}
+//	ai := tictactoe.NewMCTS(rules, tictactoe.DefaultIterations, rnd)
+//
+//	rules.Play(board, tictactoe.P1, 4)
+//	move, err := ai.Solve(board.Copy())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	rules.Play(board, tictactoe.P2, move)
+package tictactoe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Player is one of the players, P1 or P2, or Empty for a cell no one played
+// in. The players are opposite numbers, so the opponent of p is -p.
+type Player int
+
+const (
+	P1    Player = 1
+	P2    Player = -1
+	Empty Player = 0
+)
+
+// Board is a square board, its cells numbered from 0 at the top left, row by
+// row.
+type Board struct {
+	Size  int
+	Cells []Player
+}
+
+// NewBoard returns an empty board size cells wide and high.
+func NewBoard(size int) *Board {
+	cells := make([]Player, size*size)
+	for i := range cells {
+		cells[i] = Empty
+	}
+
+	return &Board{
+		Size:  size,
+		Cells: cells,
+	}
+}
+
+// Cell returns who played in the cell at index.
+func (b *Board) Cell(index int) (Player, error) {
+	if index < 0 || index >= len(b.Cells) {
+		return 0, fmt.Errorf("invalid cell index: %d", index)
+	}
+
+	return b.Cells[index], nil
+}
+
+// SetCell sets who played in the cell at index.
+func (b *Board) SetCell(index int, player Player) error {
+	if index < 0 || index >= len(b.Cells) {
+		return fmt.Errorf("invalid cell index: %d", index)
+	}
+
+	b.Cells[index] = player
+	return nil
+}
+
+// Load replaces every cell of the board with cells.
+func (b *Board) Load(cells []Player) error {
+	if len(cells) != len(b.Cells) {
+		return fmt.Errorf("invalid cells length: %d", len(cells))
+	}
+
+	copy(b.Cells, cells)
+	return nil
+}
+
+// RowCol returns the row and column of the cell at index.
+func (b *Board) RowCol(index int) (int, int, error) {
+	if index < 0 || index >= len(b.Cells) {
+		return 0, 0, fmt.Errorf("invalid cell index: %d", index)
+	}
+
+	return index / b.Size, index % b.Size, nil
+}
+
+// ChangePerspective swaps the players' cells, so the board is seen by the
+// other player.
+func (b *Board) ChangePerspective() {
+	for i := range b.Cells {
+		b.Cells[i] *= -1
+	}
+}
+
+// Copy returns a copy of the board, to be changed without changing b.
+func (b *Board) Copy() *Board {
+	newBoard := NewBoard(b.Size)
+	copy(newBoard.Cells, b.Cells)
+	return newBoard
+}
+
+// String draws the board a row per line, with O for P1, X for P2 and . for
+// empty cells.
+func (b *Board) String() string {
+	var s strings.Builder
+	for i := 0; i < b.Size; i++ {
+		for j := 0; j < b.Size; j++ {
+			cell, _ := b.Cell(i*b.Size + j)
+			if cell == P1 {
+				s.WriteString("O")
+			} else if cell == P2 {
+				s.WriteString("X")
+			} else {
+				s.WriteString(".")
+			}
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
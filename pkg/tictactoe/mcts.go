@@ -0,0 +1,256 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// exploration is how much the search favours moves it tried less, the c
+// of the UCB1 formula.
+const exploration = 1.41
+
+// DefaultIterations is how many iterations an AI of medium strength runs per
+// move. More iterations make for a stronger AI, and fewer for a weaker one.
+const DefaultIterations = 100
+
+// AI chooses moves.
+type AI interface {
+	// Solve returns the best move to play next on board, or -1 if there is
+	// none. The error is one the Game returned.
+	Solve(board *Board) (int, error)
+}
+
+// Game is the rules an AI plays by. Rules is tictactoe, and other games
+// played on a Board can be searched too.
+type Game interface {
+	// GameOver reports whether the game is over once lastMove was played,
+	// -1 standing for no move yet, and its value: WinValue if the player
+	// who played lastMove won, -WinValue if they lost, and 0 otherwise.
+	GameOver(board *Board, lastMove int) (bool, int, error)
+	// LegalMoves returns the moves that can be played.
+	LegalMoves(board *Board) []int
+	// Opponent returns the other player.
+	Opponent(player Player) Player
+	// Play plays move for player.
+	Play(board *Board, player Player, move int) error
+}
+
+type mcts struct {
+	engine     Game
+	iterations int
+	rnd        *rand.Rand
+}
+
+// NewMCTS returns an AI that plays game with Monte Carlo tree search,
+// running iterations iterations per move. There must be at least one, or
+// Solve fails. Every random choice it makes comes
+// from rnd, so an AI given the same seed plays the same moves.
+func NewMCTS(game Game, iterations int, rnd *rand.Rand) AI {
+	return &mcts{game, iterations, rnd}
+}
+
+func (m *mcts) Solve(board *Board) (int, error) {
+	if m.iterations <= 0 {
+		return -1, fmt.Errorf("an AI needs at least one iteration per move, not %d", m.iterations)
+	}
+	if len(m.engine.LegalMoves(board)) == 0 {
+		return -1, nil
+	}
+
+	root := newNode(m.engine, m.rnd, board, -1, nil)
+
+	for i := 0; i < m.iterations; i++ {
+		node := root
+		for node.isExpanded() {
+			child, err := node.selectChild()
+			if err != nil {
+				return -1, err
+			}
+			node = child
+		}
+
+		isOver, value, err := m.engine.GameOver(node.board, node.move)
+		if err != nil {
+			return -1, err
+		}
+		value = -value
+
+		if !isOver {
+			child, err := node.expand()
+			if err != nil {
+				return -1, err
+			}
+			value, err = child.simulate()
+			if err != nil {
+				return -1, err
+			}
+			node = child
+		}
+
+		node.backpropagate(value)
+	}
+
+	visits := make([]float64, board.Size*board.Size)
+	dist := make([]float64, board.Size*board.Size)
+	sum := 0.0
+
+	for _, child := range root.children {
+		visits[child.move] = float64(child.visitCount)
+		sum += visits[child.move]
+	}
+
+	for i, visit := range visits {
+		dist[i] = visit / sum
+	}
+
+	bestMove := -1
+	bestValue := 0.0
+
+	for i, value := range dist {
+		if value > bestValue {
+			bestMove = i
+			bestValue = value
+		}
+	}
+
+	return bestMove, nil
+}
+
+type node struct {
+	engine     Game
+	rnd        *rand.Rand
+	board      *Board
+	move       int
+	parent     *node
+	children   []*node
+	legalMoves []int
+	valueSum   int
+	visitCount int
+}
+
+func newNode(engine Game, rnd *rand.Rand, board *Board, move int, parent *node) *node {
+	legalMoves := engine.LegalMoves(board)
+
+	return &node{
+		engine:     engine,
+		rnd:        rnd,
+		board:      board,
+		move:       move,
+		parent:     parent,
+		children:   []*node{},
+		legalMoves: legalMoves,
+		valueSum:   0,
+		visitCount: 0,
+	}
+}
+
+// Simulate all moves until game is over;
+// Returns winner
+func (n *node) simulate() (int, error) {
+	isOver, winner, err := n.engine.GameOver(n.board, n.move)
+	if err != nil {
+		return 0, err
+	}
+	if isOver {
+		return -winner, nil
+	}
+
+	board := n.board.Copy()
+	player := P1
+	result := 0
+
+	for {
+		move, _, err := popRandomMove(n.rnd, n.engine.LegalMoves(board))
+		if err != nil {
+			break
+		}
+
+		if err := n.engine.Play(board, player, move); err != nil {
+			return 0, err
+		}
+		isOver, winner, err = n.engine.GameOver(board, move)
+		if err != nil {
+			return 0, err
+		}
+		if isOver {
+			result = winner
+			break
+		}
+
+		player = n.engine.Opponent(player)
+	}
+
+	return result, nil
+}
+
+func (n *node) expand() (*node, error) {
+	move, rest, err := popRandomMove(n.rnd, n.legalMoves)
+	if err != nil {
+		return nil, err
+	}
+
+	n.legalMoves = rest
+
+	board := n.board.Copy()
+	if err := n.engine.Play(board, P1, move); err != nil {
+		return nil, err
+	}
+
+	// Every node considers itself as p1
+	board.ChangePerspective()
+	child := newNode(n.engine, n.rnd, board, move, n)
+	n.children = append(n.children, child)
+
+	return child, nil
+}
+
+func (n *node) backpropagate(value int) {
+	n.visitCount++
+	n.valueSum += value
+
+	if n.parent != nil {
+		n.parent.backpropagate(-value)
+	}
+}
+
+// Get next child with highest UCB
+func (n *node) selectChild() (*node, error) {
+	if len(n.children) == 0 {
+		return nil, fmt.Errorf("No child nodes")
+	}
+
+	var selected *node
+	var bestValue float64 = math.Inf(-1)
+
+	for _, child := range n.children {
+		ucb := n.getUCB(child)
+		if selected == nil || ucb > bestValue {
+			selected = child
+			bestValue = ucb
+		}
+	}
+
+	return selected, nil
+}
+
+func popRandomMove(rnd *rand.Rand, legalMoves []int) (int, []int, error) {
+	if len(legalMoves) == 0 {
+		return -1, legalMoves, fmt.Errorf("No legal moves")
+	}
+
+	index := rnd.IntN(len(legalMoves))
+	move := legalMoves[index]
+	legalMoves = append(legalMoves[:index], legalMoves[index+1:]...)
+
+	return move, legalMoves, nil
+}
+
+func (n *node) isExpanded() bool {
+	return len(n.children) > 0 && len(n.legalMoves) == 0
+}
+
+func (n *node) getUCB(child *node) float64 {
+	q := 1 - ((float64(child.valueSum)/float64(child.visitCount))+1)/2
+	return q + exploration*math.Sqrt(math.Log(float64(n.visitCount))/float64(child.visitCount))
+}
@@ -0,0 +1,121 @@
+package tictactoe
+
+import "fmt"
+
+// Rules are the rules of tictactoe: players take turns to fill an empty
+// cell, and the first to fill a row, a column or a diagonal wins. A full
+// board with no winner is a draw.
+type Rules struct{}
+
+// LegalMoves returns the empty cells of board.
+func (Rules) LegalMoves(board *Board) []int {
+	var moves []int
+	for i, cell := range board.Cells {
+		if cell == Empty {
+			moves = append(moves, i)
+		}
+	}
+	return moves
+}
+
+// Play plays move, the index of a cell, for player. It is an error to play
+// off the board or in a cell someone already played in.
+func (Rules) Play(board *Board, player Player, move int) error {
+	cell, err := board.Cell(move)
+	if err != nil {
+		return err
+	}
+	if cell != Empty {
+		return fmt.Errorf("cell %d was already played in", move)
+	}
+
+	return board.SetCell(move, player)
+}
+
+// Opponent returns the other player.
+func (Rules) Opponent(player Player) Player {
+	return -player
+}
+
+// WinValue is the value of a game won by the player of its last move, as
+// GameOver reports it. A draw is worth 0, and a loss -WinValue.
+const WinValue = 1
+
+// GameOver reports whether the game is over once lastMove was played, with
+// -1 standing for no move yet. The value is WinValue if the player who
+// played lastMove won, and 0 otherwise.
+func (r Rules) GameOver(board *Board, lastMove int) (bool, int, error) {
+	if lastMove == -1 {
+		return false, 0, nil
+	}
+
+	win, err := r.Win(board, lastMove)
+	if err != nil {
+		return false, 0, err
+	}
+	if win {
+		return true, WinValue, nil
+	}
+
+	if len(r.LegalMoves(board)) == 0 {
+		return true, 0, nil
+	}
+
+	return false, 0, nil
+}
+
+// Win reports whether lastMove completed a row, a column or a diagonal. It
+// is an error for lastMove to be off the board.
+func (r Rules) Win(board *Board, lastMove int) (bool, error) {
+	player, err := board.Cell(lastMove)
+	if err != nil {
+		return false, err
+	}
+	if player == Empty {
+		return false, nil
+	}
+
+	row, col, err := board.RowCol(lastMove)
+	if err != nil {
+		return false, err
+	}
+
+	// The row and the column of lastMove, and the two diagonals.
+	lines := make([][]int, 4)
+	for i := range lines {
+		lines[i] = make([]int, board.Size)
+	}
+	for i := 0; i < board.Size; i++ {
+		lines[0][i] = row*board.Size + i
+		lines[1][i] = i*board.Size + col
+		// Left to right
+		lines[2][i] = i*board.Size + i
+		// Right to left
+		lines[3][i] = i*board.Size + board.Size - i - 1
+	}
+
+	for _, line := range lines {
+		full, err := r.filled(board, line, player)
+		if err != nil || full {
+			return full, err
+		}
+	}
+
+	return false, nil
+}
+
+// filled reports whether player played in every cell of line.
+func (Rules) filled(board *Board, line []int, player Player) (bool, error) {
+	for _, index := range line {
+		cell, err := board.Cell(index)
+		if err != nil {
+			return false, err
+		}
+
+		if cell != player {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
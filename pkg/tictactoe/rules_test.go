@@ -0,0 +1,209 @@
+package tictactoe
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+var testCases = []struct {
+	input    []Player
+	expected int
+}{
+	// #0: first row
+	{
+		input:    []Player{1, 1, 0, -1, 0, -1, 0, 0, 0},
+		expected: 2,
+	},
+	// #1: first col
+	{
+		input:    []Player{1, 0, 0, 1, -1, 0, 0, -1, 0},
+		expected: 6,
+	},
+	// #2: second col
+	{
+		input:    []Player{0, 1, 0, 0, 1, -1, 0, 0, -1},
+		expected: 7,
+	},
+	// #3: diagonal left (\)
+	{
+		input:    []Player{1, -1, 0, 0, 1, -1, 0, 0, 0},
+		expected: 8,
+	},
+	// #4: diagonal right (/)
+	{
+		input:    []Player{0, -1, 1, 0, 1, -1, 0, 0, 0},
+		expected: 6,
+	},
+	// #5: middle row
+	{
+		input:    []Player{0, 0, 0, 1, 0, 1, -1, -1, 0},
+		expected: 4,
+	},
+	// #6: last row
+	{
+		input:    []Player{0, 0, 0, -1, -1, 0, 1, 1, 0},
+		expected: 8,
+	},
+	// #7: last col
+	{
+		input:    []Player{0, 0, 1, -1, 0, 1, 0, 0, 0},
+		expected: 8,
+	},
+	// #8: No move
+	{
+		input:    []Player{1, -1, 1, -1, -1, 1, 1, 1, -1},
+		expected: -1, // Indicates no move left to win
+	},
+}
+
+func TestMCTS_Solve(t *testing.T) {
+	BOARD_SIZE := 3
+	ai := NewMCTS(Rules{}, DefaultIterations, rand.New(rand.NewPCG(1, 2)))
+
+	for _, tc := range testCases {
+		t.Run("Testing solve", func(t *testing.T) {
+			board := NewBoard(BOARD_SIZE)
+			board.Load(tc.input)
+
+			move, err := ai.Solve(board)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if move != tc.expected {
+				t.Errorf("expected move %d, got %d", tc.expected, move)
+			}
+		})
+	}
+}
+
+func TestRules_Win(t *testing.T) {
+	BOARD_SIZE := 3
+	board := NewBoard(BOARD_SIZE)
+	rules := Rules{}
+
+	t.Run("Empty board", func(t *testing.T) {
+		if win, err := rules.Win(board, 0); err != nil || win {
+			t.Error("expected no win")
+		}
+	})
+
+	t.Run("Horizontal win", func(t *testing.T) {
+		board.SetCell(0, P1)
+		board.SetCell(1, P1)
+		board.SetCell(2, P1)
+		if win, err := rules.Win(board, 2); err != nil || !win {
+			t.Error("expected win")
+		}
+	})
+
+	t.Run("Vertical win", func(t *testing.T) {
+		board = NewBoard(BOARD_SIZE)
+		board.SetCell(0, P1)
+		board.SetCell(3, P1)
+		board.SetCell(6, P1)
+		if win, err := rules.Win(board, 6); err != nil || !win {
+			t.Error("expected win")
+		}
+	})
+
+	t.Run("Left diagonal win", func(t *testing.T) {
+		board = NewBoard(BOARD_SIZE)
+		board.SetCell(0, P1)
+		board.SetCell(4, P1)
+		board.SetCell(8, P1)
+		if win, err := rules.Win(board, 8); err != nil || !win {
+			t.Error("expected win")
+		}
+	})
+
+	t.Run("Right diagonal win", func(t *testing.T) {
+		board = NewBoard(BOARD_SIZE)
+		board.SetCell(2, P1)
+		board.SetCell(4, P1)
+		board.SetCell(6, P1)
+		if win, err := rules.Win(board, 6); err != nil || !win {
+			t.Error("expected win")
+		}
+	})
+}
+
+func TestRules_Errors(t *testing.T) {
+	board := NewBoard(3)
+	rules := Rules{}
+
+	for _, move := range []int{-2, 9} {
+		if _, err := rules.Win(board, move); err == nil {
+			t.Errorf("Win(board, %d) returned no error", move)
+		}
+		if _, _, err := rules.GameOver(board, move); err == nil {
+			t.Errorf("GameOver(board, %d) returned no error", move)
+		}
+	}
+
+	if err := rules.Play(board, P1, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := rules.Play(board, P2, 4); err == nil {
+		t.Error("P2 played in a cell P1 played in")
+	}
+	if cell, _ := board.Cell(4); cell != P1 {
+		t.Errorf("the cell P1 played in holds %v", cell)
+	}
+	if err := rules.Play(board, P2, 9); err == nil {
+		t.Error("P2 played off the board")
+	}
+
+	for _, iterations := range []int{0, -1} {
+		if _, err := NewMCTS(rules, iterations, rand.New(rand.NewPCG(1, 2))).Solve(board); err == nil {
+			t.Errorf("an AI with %d iterations solved a board", iterations)
+		}
+	}
+
+	if _, err := NewMCTS(brokenRules{}, DefaultIterations, rand.New(rand.NewPCG(1, 2))).Solve(board); err == nil {
+		t.Error("Solve returned no error from GameOver")
+	}
+}
+
+// brokenRules are Rules whose GameOver fails once a move is played.
+type brokenRules struct{ Rules }
+
+func (brokenRules) GameOver(board *Board, lastMove int) (bool, int, error) {
+	if lastMove == -1 {
+		return false, 0, nil
+	}
+	return false, 0, errors.New("broken")
+}
+
+func TestRules_LegalMoves(t *testing.T) {
+	BOARD_SIZE := 4
+	board := NewBoard(BOARD_SIZE)
+	rules := Rules{}
+	moves := []int{}
+
+	t.Run("Empty board", func(t *testing.T) {
+		moves = rules.LegalMoves(board)
+		if len(moves) != BOARD_SIZE*BOARD_SIZE {
+			t.Errorf("expected %d moves, got %d", BOARD_SIZE*BOARD_SIZE, len(moves))
+		}
+	})
+
+	t.Run("Full board", func(t *testing.T) {
+		for _, move := range moves {
+			board.SetCell(move, P1)
+		}
+		moves := rules.LegalMoves(board)
+		if len(moves) != 0 {
+			t.Errorf("expected 0 moves, got %d", len(moves))
+		}
+	})
+
+	t.Run("One empty cell", func(t *testing.T) {
+		board.SetCell(0, Empty)
+		moves = rules.LegalMoves(board)
+		if len(moves) != 1 {
+			t.Errorf("expected 1 move, got %d", len(moves))
+		}
+	})
+}